@@ -0,0 +1,114 @@
+// Package app bootstraps the state shared by every subcommand: the BoltDB store backing the
+// various trackers, OAuth2 client construction per account, and a logger.
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	"golang.org/x/oauth2"
+
+	"github.com/gphotosuploader/gphotos-uploader-cli/config"
+	"github.com/gphotosuploader/gphotos-uploader-cli/log"
+	"github.com/gphotosuploader/gphotos-uploader-cli/progress"
+	"github.com/gphotosuploader/gphotos-uploader-cli/upload"
+)
+
+// dbFileName is the BoltDB file every subcommand shares, stored alongside the configuration.
+const dbFileName = "gphotos-uploader-cli.db"
+
+// tokensBucket is the BoltDB bucket 'init' writes an account's OAuth2 token to once the user
+// completes the consent flow, keyed by account.
+var tokensBucket = []byte("OAuth2Tokens")
+
+// Client holds the state every subcommand bootstraps from config before doing any work.
+type Client struct {
+	Logger        log.Interface
+	Store         *bolt.DB
+	FileTracker   *upload.FileTracker
+	UploadTracker *upload.ResumableTracker
+
+	// Progress fans out ProgressEvents published by upload/download jobs to any number of
+	// subscribers, e.g. a future TUI or HTTP status endpoint.
+	Progress *progress.Broadcaster
+
+	tokens map[string]*oauth2.Token
+}
+
+// Start opens the shared BoltDB store and builds the trackers and logger every subcommand needs.
+func Start(cfg *config.Config) (*Client, error) {
+	db, err := bolt.Open(filepath.Join(cfg.ConfigDir, dbFileName), 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("could not open store: %s", err)
+	}
+
+	fileTracker, err := upload.NewFileTracker(db)
+	if err != nil {
+		return nil, err
+	}
+
+	uploadTracker, err := upload.NewResumableTracker(db)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := loadTokens(db)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		Logger:        log.New(cfg.Debug),
+		Store:         db,
+		FileTracker:   fileTracker,
+		UploadTracker: uploadTracker,
+		Progress:      progress.NewBroadcaster(),
+		tokens:        tokens,
+	}, nil
+}
+
+// loadTokens reads every account's OAuth2 token out of tokensBucket, creating the bucket if this
+// is the first run.
+func loadTokens(db *bolt.DB) (map[string]*oauth2.Token, error) {
+	tokens := make(map[string]*oauth2.Token)
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(tokensBucket)
+		if err != nil {
+			return err
+		}
+		return bucket.ForEach(func(account, data []byte) error {
+			var token oauth2.Token
+			if err := json.Unmarshal(data, &token); err != nil {
+				return nil
+			}
+			tokens[string(account)] = &token
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not load stored credentials: %s", err)
+	}
+
+	return tokens, nil
+}
+
+// Stop releases the resources Start acquired.
+func (c *Client) Stop() error {
+	return c.Store.Close()
+}
+
+// NewOAuth2Client returns an *http.Client that authenticates as account, refreshing its token
+// via oauth2Config as needed. ctx bounds the token refresh, so Ctrl-C aborts it too.
+func (c *Client) NewOAuth2Client(ctx context.Context, oauth2Config oauth2.Config, account string) (*http.Client, error) {
+	token, ok := c.tokens[account]
+	if !ok {
+		return nil, fmt.Errorf("no stored credentials for account %q: run 'gphotos-uploader-cli init'", account)
+	}
+	return oauth2Config.Client(ctx, token), nil
+}
@@ -0,0 +1,455 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	gphotos "github.com/gphotosuploader/google-photos-api-client-go/lib-gphotos"
+	"github.com/gphotosuploader/googlemirror/api/photoslibrary/v1"
+	"github.com/spf13/cobra"
+	"golang.org/x/oauth2"
+
+	"github.com/gphotosuploader/gphotos-uploader-cli/app"
+	"github.com/gphotosuploader/gphotos-uploader-cli/cmd/flags"
+	"github.com/gphotosuploader/gphotos-uploader-cli/config"
+	"github.com/gphotosuploader/gphotos-uploader-cli/dedupe"
+	"github.com/gphotosuploader/gphotos-uploader-cli/photos"
+	"github.com/gphotosuploader/gphotos-uploader-cli/ratelimit"
+	"github.com/gphotosuploader/gphotos-uploader-cli/worker"
+)
+
+// previewSize is the bounded preview Google Photos is asked to serve for fingerprinting: large
+// enough for a meaningful dHash, small enough to keep the request cheap.
+const previewSize = "w512-h512"
+
+// DedupeCmd holds the required data for the dedupe cmd
+type DedupeCmd struct {
+	*flags.GlobalFlags
+
+	// command flags
+	NumberOfWorkers  int
+	Mode             string
+	HammingThreshold int
+	KeeperStrategy   string
+	DryRun           bool
+	Yes              bool
+	Interactive      bool
+}
+
+func NewDedupeCmd(globalFlags *flags.GlobalFlags) *cobra.Command {
+	cmd := &DedupeCmd{GlobalFlags: globalFlags}
+
+	dedupeCmd := &cobra.Command{
+		Use:   "dedupe",
+		Short: "Dedupe albums",
+		Long:  `Dedupe albums. In "album" mode (the default), albums that share the same title are merged. In "media" mode, duplicate media items inside each album are found by content fingerprint and reported.`,
+		Args:  cobra.NoArgs,
+		RunE:  cmd.Run,
+	}
+
+	dedupeCmd.Flags().IntVar(&cmd.NumberOfWorkers, "workers", 1, "Number of workers")
+	dedupeCmd.Flags().StringVar(&cmd.Mode, "mode", "album", `Dedupe mode: "album" merges same-titled albums, "media" finds duplicate media items within albums`)
+	dedupeCmd.Flags().IntVar(&cmd.HammingThreshold, "hamming-threshold", dedupe.DefaultHammingThreshold, "Maximum perceptual hash distance for two media items to be considered duplicates")
+	dedupeCmd.Flags().StringVar(&cmd.KeeperStrategy, "keeper-strategy", string(dedupe.MostItems), `Which album to keep on merge: "most-items", "oldest", "newest" or "title-exact-match"`)
+	dedupeCmd.Flags().BoolVar(&cmd.DryRun, "dry-run", false, "Compute the merge plan and write it to dedupe-plan.json/.txt without merging anything")
+	dedupeCmd.Flags().BoolVar(&cmd.Yes, "yes", false, "Merge every pair without prompting, even in --interactive mode")
+	dedupeCmd.Flags().BoolVar(&cmd.Interactive, "interactive", false, "Prompt for confirmation before merging each pair of albums")
+
+	dedupeCmd.AddCommand(NewDedupeCleanupStatusCmd(globalFlags))
+
+	return dedupeCmd
+}
+
+func getAllPhotosFromAlbum(ctx context.Context, photosService *gphotos.Client, limiter *ratelimit.Limiter, albumId string) ([]*photoslibrary.MediaItem, error) {
+	photos := make([]*photoslibrary.MediaItem, 0)
+
+	token := ""
+
+get_page:
+	if err := limiter.WaitForRequest(ctx); err != nil {
+		return nil, err
+	}
+	search1, err := photosService.MediaItems.Search(&photoslibrary.SearchMediaItemsRequest{
+		AlbumId:   albumId,
+		PageToken: token,
+	}).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+	photos = append(photos, search1.MediaItems...)
+
+	if len(search1.NextPageToken) > 0 {
+		token = search1.NextPageToken
+		//fmt.Println(search1.NextPageToken)
+		goto get_page
+	}
+
+	return photos, nil
+}
+
+func addPhotosToAlbum(ctx context.Context, photosService *gphotos.Client, limiter *ratelimit.Limiter, albumId string, mediaitems []string) error {
+	chunkSize := 50 //max number of items per request
+	for i := 0; i < len(mediaitems); i += chunkSize {
+		end := i + chunkSize
+		if end > len(mediaitems) {
+			end = len(mediaitems)
+		}
+		if err := limiter.WaitForRequest(ctx); err != nil {
+			return err
+		}
+		_, err := photosService.Albums.BatchAddMediaItems(albumId, &photoslibrary.AlbumBatchAddMediaItemsRequest{MediaItemIds: mediaitems[i:end]}).Context(ctx).Do()
+		if err != nil {
+			return err
+		}
+	}
+	//fmt.Println("Added", len(mediaitems), "items")
+	return nil
+}
+
+// computeAlbumPair fetches both albums and their items, decides which one should be kept per
+// strategy, and reports the merge as a dedupe.AlbumPair without mutating anything. The caller
+// decides whether and how to act on it.
+func computeAlbumPair(ctx context.Context, photosService *gphotos.Client, limiter *ratelimit.Limiter, strategy dedupe.KeeperStrategy, id1, id2 string) (dedupe.AlbumPair, *photoslibrary.Album, *photoslibrary.Album, []string, error) {
+	if err := limiter.WaitForRequest(ctx); err != nil {
+		return dedupe.AlbumPair{}, nil, nil, nil, err
+	}
+	a1, err := photosService.Albums.Get(id1).Context(ctx).Do()
+	if err != nil {
+		return dedupe.AlbumPair{}, nil, nil, nil, err
+	}
+	if err := limiter.WaitForRequest(ctx); err != nil {
+		return dedupe.AlbumPair{}, nil, nil, nil, err
+	}
+	a2, err := photosService.Albums.Get(id2).Context(ctx).Do()
+	if err != nil {
+		return dedupe.AlbumPair{}, nil, nil, nil, err
+	}
+
+	photos1, err := getAllPhotosFromAlbum(ctx, photosService, limiter, id1)
+	if err != nil {
+		return dedupe.AlbumPair{}, nil, nil, nil, err
+	}
+	photos2, err := getAllPhotosFromAlbum(ctx, photosService, limiter, id2)
+	if err != nil {
+		return dedupe.AlbumPair{}, nil, nil, nil, err
+	}
+
+	fmt.Printf("Dedupe '%s' (%d) and '%s' (%d)\n", a1.Title, len(photos1), a2.Title, len(photos2))
+
+	keep, discard, keepItems, discardItems := dedupe.ChooseKeeper(strategy, a1, a2, photos1, photos2)
+
+	discardItemIDs := make([]string, 0, len(discardItems))
+	for _, x := range discardItems {
+		discardItemIDs = append(discardItemIDs, x.Id)
+	}
+
+	pair := dedupe.AlbumPair{
+		KeptAlbumID:       keep.Id,
+		KeptTitle:         keep.Title,
+		KeptProductUrl:    keep.ProductUrl,
+		KeptItemCount:     len(keepItems),
+		DeletedAlbumID:    discard.Id,
+		DeletedTitle:      discard.Title,
+		DeletedProductUrl: discard.ProductUrl,
+		DeletedItemCount:  len(discardItems),
+	}
+
+	return pair, keep, discard, discardItemIDs, nil
+}
+
+// confirmMerge prompts the user on stdin for a yes/no answer before merging a pair of albums.
+func confirmMerge(pair dedupe.AlbumPair) bool {
+	fmt.Printf("Merge %q (%s, %d items) into %q (%s, %d items)? [y/N]: ",
+		pair.DeletedTitle, pair.DeletedProductUrl, pair.DeletedItemCount,
+		pair.KeptTitle, pair.KeptProductUrl, pair.KeptItemCount)
+
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.TrimSpace(strings.ToLower(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// mediaDedupe fingerprints every media item in the given album, using store as an incremental
+// cache, and returns the duplicate clusters found.
+func mediaDedupe(ctx context.Context, photosService *gphotos.Client, limiter *ratelimit.Limiter, store *dedupe.Store, album *photoslibrary.Album, threshold int) (dedupe.Report, error) {
+	items, err := getAllPhotosFromAlbum(ctx, photosService, limiter, album.Id)
+	if err != nil {
+		return dedupe.Report{}, err
+	}
+
+	refs := make([]dedupe.MediaRef, 0, len(items))
+	fingerprints := make(map[string]dedupe.Fingerprint, len(items))
+
+	for _, mi := range items {
+		width, height := parseDimensions(mi.MediaMetadata.Width, mi.MediaMetadata.Height)
+		ref := dedupe.MediaRef{
+			MediaItemID:  mi.Id,
+			ProductUrl:   mi.ProductUrl,
+			CreationTime: mi.MediaMetadata.CreationTime,
+			Width:        width,
+			Height:       height,
+		}
+
+		if record, ok := store.Get(mi.Id); ok && record.CreationTime == mi.MediaMetadata.CreationTime {
+			refs = append(refs, ref)
+			fingerprints[mi.Id] = record.Fingerprint
+			continue
+		}
+
+		fp, err := fetchFingerprint(ctx, mi.BaseUrl)
+		if err != nil {
+			return dedupe.Report{}, fmt.Errorf("could not fingerprint %s: %s", mi.Id, err)
+		}
+
+		if err := store.Put(mi.Id, dedupe.Record{Fingerprint: fp, CreationTime: mi.MediaMetadata.CreationTime}); err != nil {
+			return dedupe.Report{}, err
+		}
+
+		refs = append(refs, ref)
+		fingerprints[mi.Id] = fp
+	}
+
+	return dedupe.Report{
+		AlbumTitle: album.Title,
+		Clusters:   dedupe.FindClusters(refs, fingerprints, threshold),
+	}, nil
+}
+
+// parseDimensions parses MediaMetadata's Width/Height, which the Library API reports as
+// int64-format strings. A malformed value (or none) yields 0, the same "unknown" a real
+// zero-size image would produce.
+func parseDimensions(width, height string) (int, int) {
+	w, _ := strconv.Atoi(width)
+	h, _ := strconv.Atoi(height)
+	return w, h
+}
+
+func fetchFingerprint(ctx context.Context, baseUrl string) (dedupe.Fingerprint, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseUrl+"="+previewSize, nil)
+	if err != nil {
+		return dedupe.Fingerprint{}, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return dedupe.Fingerprint{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return dedupe.Fingerprint{}, fmt.Errorf("unexpected status fetching preview: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return dedupe.Fingerprint{}, err
+	}
+
+	return dedupe.Compute(data)
+}
+
+func (cmd *DedupeCmd) Run(cobraCmd *cobra.Command, args []string) error {
+	cfg, err := config.LoadConfigAndValidate(cmd.CfgDir)
+	if err != nil {
+		return fmt.Errorf("please review your configuration or run 'gphotos-uploader-cli init': file=%s, err=%s", cmd.CfgDir, err)
+	}
+
+	cli, err := app.Start(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = cli.Stop()
+	}()
+
+	// get OAuth2 Configuration with our App credentials
+	oauth2Config := oauth2.Config{
+		ClientID:     cfg.APIAppCredentials.ClientID,
+		ClientSecret: cfg.APIAppCredentials.ClientSecret,
+		Endpoint:     photos.Endpoint,
+		Scopes:       photos.Scopes,
+	}
+
+	uploadQueue := worker.NewJobQueue(cmd.NumberOfWorkers, cli.Logger)
+	uploadQueue.Start()
+	defer uploadQueue.Stop()
+	time.Sleep(1 * time.Second) // sleeps to avoid log messages colliding with output.
+
+	var store *dedupe.Store
+	if cmd.Mode == "media" {
+		store, err = dedupe.NewStore(cli.Store)
+		if err != nil {
+			return err
+		}
+	}
+
+	keeperStrategy, err := dedupe.ParseKeeperStrategy(cmd.KeeperStrategy)
+	if err != nil {
+		return err
+	}
+
+	var cleanupQueue *dedupe.CleanupQueue
+	if cmd.Mode != "media" && !cmd.DryRun {
+		cleanupQueue, err = dedupe.NewCleanupQueue(cli.Store)
+		if err != nil {
+			return err
+		}
+	}
+
+	// ctx is cancelled on Ctrl-C/SIGTERM, which aborts in-flight HTTP calls instead of only
+	// stopping new dispatches.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	limiters := ratelimit.NewRegistry(cfg.RateLimit.RateLimitOrDefault())
+
+	var allPairs []dedupe.AlbumPair
+
+	for _, config := range cfg.Jobs {
+		c, err := cli.NewOAuth2Client(ctx, oauth2Config, config.Account)
+		if err != nil {
+			return err
+		}
+
+		photosService, err := gphotos.NewClientWithResumableUploads(c, cli.UploadTracker)
+		if err != nil {
+			return err
+		}
+
+		limiter := limiters.For(config.Account)
+
+		if cmd.Mode == "media" {
+			if err := cmd.runMediaDedupe(ctx, photosService, limiter, store); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := cmd.runAlbumDedupe(ctx, photosService, limiter, config.Account, keeperStrategy, cleanupQueue, &allPairs); err != nil {
+			return err
+		}
+	}
+
+	if cmd.Mode != "media" && cmd.DryRun {
+		plan := dedupe.MergePlan{Strategy: keeperStrategy, Pairs: allPairs}
+		if err := dedupe.WritePlanJSONFile("dedupe-plan.json", plan); err != nil {
+			return err
+		}
+		if err := dedupe.WritePlanTextFile("dedupe-plan.txt", plan); err != nil {
+			return err
+		}
+		fmt.Printf("Dry run: %d album pair(s) would be merged, see dedupe-plan.json and dedupe-plan.txt\n", len(allPairs))
+	}
+
+	return nil
+}
+
+func (cmd *DedupeCmd) runAlbumDedupe(ctx context.Context, photosService *gphotos.Client, limiter *ratelimit.Limiter, account string, strategy dedupe.KeeperStrategy, cleanupQueue *dedupe.CleanupQueue, pairs *[]dedupe.AlbumPair) error {
+	token := ""
+
+	albums := make(map[string]string)
+
+	parsed := 0
+
+get_page:
+	if err := limiter.WaitForRequest(ctx); err != nil {
+		return err
+	}
+	resp, err := photosService.Albums.List().PageSize(50).PageToken(token).Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+
+	for _, album := range resp.Albums {
+		if dupe_id, exists := albums[album.Title]; exists {
+			pair, keep, discard, discardItemIDs, err := computeAlbumPair(ctx, photosService, limiter, strategy, dupe_id, album.Id)
+			if err != nil {
+				return err
+			}
+			*pairs = append(*pairs, pair)
+			albums[album.Title] = keep.Id
+
+			if cmd.DryRun {
+				parsed += 1
+				continue
+			}
+
+			if cmd.Interactive && !cmd.Yes && !confirmMerge(pair) {
+				fmt.Printf("Skipped merging %q into %q\n", discard.Title, keep.Title)
+				parsed += 1
+				continue
+			}
+
+			if err := addPhotosToAlbum(ctx, photosService, limiter, keep.Id, discardItemIDs); err != nil {
+				return err
+			}
+			if cleanupQueue != nil {
+				entry := dedupe.CleanupEntry{Account: account, AlbumID: discard.Id, Title: discard.Title, ProductUrl: discard.ProductUrl}
+				if err := cleanupQueue.Add(entry); err != nil {
+					return err
+				}
+			}
+			fmt.Println("To delete: ", discard.ProductUrl)
+		} else {
+			albums[album.Title] = album.Id
+		}
+		parsed += 1
+		//fmt.Println("Parsed:", parsed)
+	}
+
+	token = resp.NextPageToken
+	if len(token) > 0 {
+		//fmt.Println(token)
+		fmt.Println("Parsed", parsed, "albums")
+		goto get_page
+	}
+
+	return nil
+}
+
+func (cmd *DedupeCmd) runMediaDedupe(ctx context.Context, photosService *gphotos.Client, limiter *ratelimit.Limiter, store *dedupe.Store) error {
+	token := ""
+
+get_page:
+	if err := limiter.WaitForRequest(ctx); err != nil {
+		return err
+	}
+	resp, err := photosService.Albums.List().PageSize(50).PageToken(token).Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+
+	for _, album := range resp.Albums {
+		report, err := mediaDedupe(ctx, photosService, limiter, store, album, cmd.HammingThreshold)
+		if err != nil {
+			return err
+		}
+		if len(report.Clusters) == 0 {
+			continue
+		}
+
+		jsonPath := fmt.Sprintf("dedupe-report-%s.json", album.Id)
+		textPath := fmt.Sprintf("dedupe-report-%s.txt", album.Id)
+		if err := dedupe.WriteJSONFile(jsonPath, report); err != nil {
+			return err
+		}
+		if err := dedupe.WriteTextFile(textPath, report); err != nil {
+			return err
+		}
+		fmt.Printf("Found %d duplicate cluster(s) in album '%s', see %s and %s\n", len(report.Clusters), album.Title, jsonPath, textPath)
+	}
+
+	if len(resp.NextPageToken) > 0 {
+		token = resp.NextPageToken
+		goto get_page
+	}
+
+	return nil
+}
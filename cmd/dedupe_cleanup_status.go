@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	gphotos "github.com/gphotosuploader/google-photos-api-client-go/lib-gphotos"
+	"github.com/spf13/cobra"
+	"golang.org/x/oauth2"
+	"google.golang.org/api/googleapi"
+
+	"github.com/gphotosuploader/gphotos-uploader-cli/app"
+	"github.com/gphotosuploader/gphotos-uploader-cli/cmd/flags"
+	"github.com/gphotosuploader/gphotos-uploader-cli/config"
+	"github.com/gphotosuploader/gphotos-uploader-cli/dedupe"
+	"github.com/gphotosuploader/gphotos-uploader-cli/photos"
+	"github.com/gphotosuploader/gphotos-uploader-cli/ratelimit"
+)
+
+// DedupeCleanupStatusCmd holds the required data for the "dedupe cleanup-status" cmd.
+type DedupeCleanupStatusCmd struct {
+	*flags.GlobalFlags
+}
+
+func NewDedupeCleanupStatusCmd(globalFlags *flags.GlobalFlags) *cobra.Command {
+	cmd := &DedupeCleanupStatusCmd{GlobalFlags: globalFlags}
+
+	return &cobra.Command{
+		Use:   "cleanup-status",
+		Short: "List albums still pending manual deletion after dedupe",
+		Long:  `List the albums "dedupe" merged away, which the Library API cannot delete for you. Entries whose album has since been deleted or renamed are dropped from the queue.`,
+		Args:  cobra.NoArgs,
+		RunE:  cmd.Run,
+	}
+}
+
+func (cmd *DedupeCleanupStatusCmd) Run(_ *cobra.Command, _ []string) error {
+	cfg, err := config.LoadConfigAndValidate(cmd.CfgDir)
+	if err != nil {
+		return fmt.Errorf("please review your configuration or run 'gphotos-uploader-cli init': file=%s, err=%s", cmd.CfgDir, err)
+	}
+
+	cli, err := app.Start(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = cli.Stop()
+	}()
+
+	queue, err := dedupe.NewCleanupQueue(cli.Store)
+	if err != nil {
+		return err
+	}
+
+	entries, err := queue.List()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Println("No albums pending manual deletion.")
+		return nil
+	}
+
+	entriesByAccount := make(map[string][]dedupe.CleanupEntry)
+	for _, entry := range entries {
+		entriesByAccount[entry.Account] = append(entriesByAccount[entry.Account], entry)
+	}
+
+	// get OAuth2 Configuration with our App credentials
+	oauth2Config := oauth2.Config{
+		ClientID:     cfg.APIAppCredentials.ClientID,
+		ClientSecret: cfg.APIAppCredentials.ClientSecret,
+		Endpoint:     photos.Endpoint,
+		Scopes:       photos.Scopes,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	limiters := ratelimit.NewRegistry(cfg.RateLimit.RateLimitOrDefault())
+
+	pending := 0
+	for _, job := range cfg.Jobs {
+		accountEntries, ok := entriesByAccount[job.Account]
+		if !ok {
+			continue
+		}
+
+		c, err := cli.NewOAuth2Client(ctx, oauth2Config, job.Account)
+		if err != nil {
+			return err
+		}
+
+		photosService, err := gphotos.NewClientWithResumableUploads(c, cli.UploadTracker)
+		if err != nil {
+			return err
+		}
+
+		limiter := limiters.For(job.Account)
+
+		for _, entry := range accountEntries {
+			if err := limiter.WaitForRequest(ctx); err != nil {
+				return err
+			}
+
+			album, err := photosService.Albums.Get(entry.AlbumID).Context(ctx).Do()
+			if err != nil {
+				if !isNotFound(err) {
+					// A transient error (network blip, 429, Ctrl-C) doesn't mean the album is
+					// gone: leave the entry queued so the user doesn't lose track of it.
+					return err
+				}
+				if err := queue.Remove(entry.AlbumID); err != nil {
+					return err
+				}
+				continue
+			}
+			if album.Title != entry.Title {
+				if err := queue.Remove(entry.AlbumID); err != nil {
+					return err
+				}
+				continue
+			}
+
+			fmt.Printf("Still pending: %q %s\n", entry.Title, entry.ProductUrl)
+			pending++
+		}
+	}
+
+	fmt.Printf("%d album(s) still pending manual deletion.\n", pending)
+	return nil
+}
+
+// isNotFound reports whether err is a confirmed 404 from the Library API, as opposed to a
+// transient error that says nothing about whether the album still exists.
+func isNotFound(err error) bool {
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code == http.StatusNotFound
+	}
+	return false
+}
@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/gphotosuploader/gphotos-uploader-cli/ratelimit"
+)
+
+// ExitCodeQuotaExceeded is returned instead of the default failure exit code when a command's
+// error wraps ratelimit.ErrQuotaExceeded (push and pull return it once any item gave up after
+// repeated 429 responses), so scripts can tell quota exhaustion apart from a real error.
+const ExitCodeQuotaExceeded = 2
+
+// ExitCodeFor maps an error returned by a command's Run to the process exit code main should use.
+func ExitCodeFor(err error) int {
+	if err == nil {
+		return 0
+	}
+	if errors.Is(err, ratelimit.ErrQuotaExceeded) {
+		return ExitCodeQuotaExceeded
+	}
+	return 1
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"path/filepath"
+
+	"github.com/cheggaaa/pb/v3"
+
+	"github.com/gphotosuploader/gphotos-uploader-cli/progress"
+)
+
+// largeFileThreshold is the minimum BytesTotal a job's events must report before it earns the
+// per-file bar; smaller files only move the aggregate bar.
+const largeFileThreshold = 10 * 1024 * 1024 // 10MiB
+
+// progressPool is an aggregate items-done bar plus a single byte-level bar that tracks whichever
+// large file is currently uploading/downloading, fed by a progress.Broadcaster subscription.
+type progressPool struct {
+	pool    *pb.Pool
+	total   *pb.ProgressBar
+	sub     <-chan progress.Event
+	stop    chan struct{}
+	current string
+}
+
+// newProgressPool builds a progressPool for totalItems jobs and starts consuming sub in the
+// background. Call Start to render it and Stop to tear it down once the queue is drained.
+func newProgressPool(b *progress.Broadcaster, totalItems int) *progressPool {
+	p := &progressPool{
+		total: pb.New(totalItems),
+		sub:   b.Subscribe(),
+		stop:  make(chan struct{}),
+	}
+
+	fileBar := pb.New64(0)
+	fileBar.SetTemplateString(`{{ string . "prefix" }} {{ bar . }} {{ percent . }}`)
+	p.pool = pb.NewPool(p.total, fileBar)
+
+	go p.render(fileBar, b)
+
+	return p
+}
+
+// render updates fileBar from incoming events until Stop is called, picking up the most recently
+// active large file and clearing the bar once that file is done or failed.
+func (p *progressPool) render(fileBar *pb.ProgressBar, b *progress.Broadcaster) {
+	defer b.Unsubscribe(p.sub)
+
+	for {
+		select {
+		case ev, ok := <-p.sub:
+			if !ok {
+				return
+			}
+			if ev.BytesTotal < largeFileThreshold {
+				continue
+			}
+			if ev.Phase == progress.PhaseDone || ev.Phase == progress.PhaseFailed {
+				if ev.JobID == p.current {
+					p.current = ""
+					fileBar.SetCurrent(0)
+					fileBar.Set("prefix", "")
+				}
+				continue
+			}
+			p.current = ev.JobID
+			fileBar.SetTotal(ev.BytesTotal)
+			fileBar.SetCurrent(ev.BytesDone)
+			fileBar.Set("prefix", filepath.Base(ev.Path))
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+// Start renders the pool.
+func (p *progressPool) Start() error {
+	return p.pool.Start()
+}
+
+// Increment advances the aggregate bar by one completed job.
+func (p *progressPool) Increment() {
+	p.total.Increment()
+}
+
+// Stop stops consuming events and tears down the rendered pool.
+func (p *progressPool) Stop() {
+	close(p.stop)
+	_ = p.pool.Stop()
+}
@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	gphotos "github.com/gphotosuploader/google-photos-api-client-go/lib-gphotos"
+	"github.com/spf13/cobra"
+	"golang.org/x/oauth2"
+
+	"github.com/gphotosuploader/gphotos-uploader-cli/app"
+	"github.com/gphotosuploader/gphotos-uploader-cli/cmd/flags"
+	"github.com/gphotosuploader/gphotos-uploader-cli/config"
+	"github.com/gphotosuploader/gphotos-uploader-cli/download"
+	"github.com/gphotosuploader/gphotos-uploader-cli/pattern"
+	"github.com/gphotosuploader/gphotos-uploader-cli/photos"
+	"github.com/gphotosuploader/gphotos-uploader-cli/ratelimit"
+	"github.com/gphotosuploader/gphotos-uploader-cli/upload"
+	"github.com/gphotosuploader/gphotos-uploader-cli/worker"
+)
+
+// PullCmd holds the required data for the pull cmd
+type PullCmd struct {
+	*flags.GlobalFlags
+
+	// command flags
+	NumberOfWorkers int
+	NoProgress      bool
+	Silent          bool
+}
+
+func NewPullCmd(globalFlags *flags.GlobalFlags) *cobra.Command {
+	cmd := &PullCmd{GlobalFlags: globalFlags}
+
+	pullCmd := &cobra.Command{
+		Use:   "pull",
+		Short: "Pull files from Google Photos service to local disk",
+		Long:  `Download media from Google Photos to the destination folders configured for each pull job, skipping items already downloaded.`,
+		Args:  cobra.NoArgs,
+		RunE:  cmd.Run,
+	}
+
+	pullCmd.Flags().IntVar(&cmd.NumberOfWorkers, "workers", 5, "Number of workers")
+	pullCmd.Flags().BoolVar(&cmd.NoProgress, "no-progress", false, "Disable the progress bar and fall back to log-only output")
+	pullCmd.Flags().BoolVar(&cmd.Silent, "silent", false, "Alias for --no-progress")
+
+	return pullCmd
+}
+
+func (cmd *PullCmd) Run(cobraCmd *cobra.Command, args []string) error {
+	cfg, err := config.LoadConfigAndValidate(cmd.CfgDir)
+	if err != nil {
+		return fmt.Errorf("please review your configuration or run 'gphotos-uploader-cli init': file=%s, err=%s", cmd.CfgDir, err)
+	}
+
+	cli, err := app.Start(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = cli.Stop()
+	}()
+
+	tracker, err := download.NewTracker(cli.Store)
+	if err != nil {
+		return err
+	}
+
+	// get OAuth2 Configuration with our App credentials
+	oauth2Config := oauth2.Config{
+		ClientID:     cfg.APIAppCredentials.ClientID,
+		ClientSecret: cfg.APIAppCredentials.ClientSecret,
+		Endpoint:     photos.Endpoint,
+		Scopes:       photos.Scopes,
+	}
+
+	downloadQueue := worker.NewJobQueue(cmd.NumberOfWorkers, cli.Logger)
+	downloadQueue.Start()
+	defer downloadQueue.Stop()
+	time.Sleep(1 * time.Second) // sleeps to avoid log messages colliding with output.
+
+	// ctx is cancelled on Ctrl-C/SIGTERM, which aborts in-flight HTTP calls instead of only
+	// stopping new dispatches.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	limiters := ratelimit.NewRegistry(cfg.RateLimit.RateLimitOrDefault())
+
+	var totalItems int
+	for _, pullJob := range cfg.PullJobs {
+		c, err := cli.NewOAuth2Client(ctx, oauth2Config, pullJob.Account)
+		if err != nil {
+			return err
+		}
+
+		photosService, err := gphotos.NewClientWithResumableUploads(c, cli.UploadTracker)
+		if err != nil {
+			return err
+		}
+
+		var layout *pattern.Pattern
+		if pullJob.Layout != "" {
+			layout, err = pattern.Compile(pullJob.Layout)
+			if err != nil {
+				return fmt.Errorf("invalid layout for pull job into '%s': %s", pullJob.DestinationFolder, err)
+			}
+		}
+
+		folder := download.FolderJob{
+			Tracker: tracker,
+			Logger:  cli.Logger,
+
+			DestinationFolder: pullJob.DestinationFolder,
+			Album:             pullJob.Album,
+			DateFrom:          pullJob.DateFrom,
+			DateTo:            pullJob.DateTo,
+			FlatLayout:        pullJob.FlatLayout,
+			Layout:            layout,
+			Filter:            upload.NewFilter(pullJob.IncludePatterns, pullJob.ExcludePatterns, true),
+			Limiter:           limiters.For(pullJob.Account),
+		}
+
+		itemsToDownload, err := folder.Plan(ctx, photosService)
+		if err != nil {
+			cli.Logger.Fatalf("Failed to plan download for '%s': %v", pullJob.DestinationFolder, err)
+		}
+
+		cli.Logger.Infof("%d items pending to be downloaded into '%s'.", len(itemsToDownload), pullJob.DestinationFolder)
+		totalItems += len(itemsToDownload)
+		for _, i := range itemsToDownload {
+			downloadQueue.Submit(&worker.DownloadJob{
+				Context:     ctx,
+				Tracker:     tracker,
+				Broadcaster: cli.Progress,
+				Limiter:     limiters.For(pullJob.Account),
+
+				MediaItemID:  i.MediaItemID,
+				BaseURL:      i.BaseURL,
+				CreationTime: i.CreationTime,
+				IsVideo:      i.IsVideo,
+				DestPath:     i.DestPath,
+			})
+		}
+	}
+
+	var bars *progressPool
+	if !cmd.NoProgress && !cmd.Silent {
+		bars = newProgressPool(cli.Progress, totalItems)
+		if err := bars.Start(); err != nil {
+			cli.Logger.Infof("Could not start the progress bar, falling back to log-only output: %s", err)
+			bars.Stop()
+			bars = nil
+		}
+	}
+
+	var downloadedItems int
+	var quotaExceeded bool
+	for i := 0; i < totalItems; i++ {
+		r := <-downloadQueue.ChanJobResults()
+
+		if bars != nil {
+			bars.Increment()
+		}
+		if errors.Is(r.Err, ratelimit.ErrQuotaExceeded) {
+			quotaExceeded = true
+		}
+		if r.Err != nil {
+			cli.Logger.Failf("Error processing %s", r.ID)
+		} else {
+			downloadedItems++
+			cli.Logger.Debugf("Successfully processing %s", r.ID)
+		}
+	}
+
+	if bars != nil {
+		bars.Stop()
+	}
+
+	cli.Logger.Infof("%d processed items: %d successfully, %d with errors", totalItems, downloadedItems, totalItems-downloadedItems)
+	if quotaExceeded {
+		return ratelimit.ErrQuotaExceeded
+	}
+	return nil
+}
@@ -2,18 +2,23 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	gphotos "github.com/gphotosuploader/google-photos-api-client-go/lib-gphotos"
-	"github.com/gphotosuploader/googlemirror/api/photoslibrary/v1"
 	"github.com/spf13/cobra"
 	"golang.org/x/oauth2"
 
 	"github.com/gphotosuploader/gphotos-uploader-cli/app"
 	"github.com/gphotosuploader/gphotos-uploader-cli/cmd/flags"
 	"github.com/gphotosuploader/gphotos-uploader-cli/config"
+	"github.com/gphotosuploader/gphotos-uploader-cli/pattern"
 	"github.com/gphotosuploader/gphotos-uploader-cli/photos"
+	"github.com/gphotosuploader/gphotos-uploader-cli/ratelimit"
 	"github.com/gphotosuploader/gphotos-uploader-cli/upload"
 	"github.com/gphotosuploader/gphotos-uploader-cli/worker"
 )
@@ -24,30 +29,8 @@ type PushCmd struct {
 
 	// command flags
 	NumberOfWorkers int
-}
-
-// DedupeCmd holds the required data for the push cmd
-type DedupeCmd struct {
-	*flags.GlobalFlags
-
-	// command flags
-	NumberOfWorkers int
-}
-
-func NewDedupeCmd(globalFlags *flags.GlobalFlags) *cobra.Command {
-	cmd := &DedupeCmd{GlobalFlags: globalFlags}
-
-	pushCmd := &cobra.Command{
-		Use:   "dedupe",
-		Short: "Dedupe albums",
-		Long:  `Dedupe albums.`,
-		Args:  cobra.NoArgs,
-		RunE:  cmd.Run,
-	}
-
-	pushCmd.Flags().IntVar(&cmd.NumberOfWorkers, "workers", 1, "Number of workers")
-
-	return pushCmd
+	NoProgress      bool
+	Silent          bool
 }
 
 func NewPushCmd(globalFlags *flags.GlobalFlags) *cobra.Command {
@@ -62,6 +45,8 @@ func NewPushCmd(globalFlags *flags.GlobalFlags) *cobra.Command {
 	}
 
 	pushCmd.Flags().IntVar(&cmd.NumberOfWorkers, "workers", 5, "Number of workers")
+	pushCmd.Flags().BoolVar(&cmd.NoProgress, "no-progress", false, "Disable the progress bar and fall back to log-only output")
+	pushCmd.Flags().BoolVar(&cmd.Silent, "silent", false, "Alias for --no-progress")
 
 	return pushCmd
 }
@@ -93,8 +78,14 @@ func (cmd *PushCmd) Run(cobraCmd *cobra.Command, args []string) error {
 	defer uploadQueue.Stop()
 	time.Sleep(1 * time.Second) // sleeps to avoid log messages colliding with output.
 
+	// ctx is cancelled on Ctrl-C/SIGTERM, which aborts in-flight HTTP calls instead of only
+	// stopping new dispatches.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	limiters := ratelimit.NewRegistry(cfg.RateLimit.RateLimitOrDefault())
+
 	// launch all folder upload jobs
-	ctx := context.Background()
 	var totalItems int
 	for _, config := range cfg.Jobs {
 		c, err := cli.NewOAuth2Client(ctx, oauth2Config, config.Account)
@@ -107,12 +98,28 @@ func (cmd *PushCmd) Run(cobraCmd *cobra.Command, args []string) error {
 			return err
 		}
 
+		var layout *pattern.Pattern
+		if config.Layout != "" {
+			layout, err = pattern.Compile(config.Layout)
+			if err != nil {
+				return fmt.Errorf("invalid layout for folder '%s': %s", config.SourceFolder, err)
+			}
+		}
+
+		createAlbum := config.MakeAlbums.Enabled
+		createAlbumBasedOn := config.MakeAlbums.Use
+		if layout != nil {
+			createAlbum = upload.HasAlbumPlaceholder(layout)
+			createAlbumBasedOn = ""
+		}
+
 		folder := upload.UploadFolderJob{
 			FileTracker: cli.FileTracker,
 
 			SourceFolder:       config.SourceFolder,
-			CreateAlbum:        config.MakeAlbums.Enabled,
-			CreateAlbumBasedOn: config.MakeAlbums.Use,
+			Layout:             layout,
+			CreateAlbum:        createAlbum,
+			CreateAlbumBasedOn: createAlbumBasedOn,
 			Filter:             upload.NewFilter(config.IncludePatterns, config.ExcludePatterns, config.UploadVideos),
 		}
 
@@ -126,24 +133,50 @@ func (cmd *PushCmd) Run(cobraCmd *cobra.Command, args []string) error {
 		cli.Logger.Infof("%d files pending to be uploaded in folder '%s'.", len(itemsToUpload), config.SourceFolder)
 		totalItems += len(itemsToUpload)
 		for _, i := range itemsToUpload {
+			albumName := i.AlbumName
+			if layout != nil {
+				if album := upload.AlbumForPath(layout, config.SourceFolder, i.Path); album != "" {
+					albumName = album
+				}
+			}
+
 			uploadQueue.Submit(&upload.EnqueuedJob{
 				Context:       ctx,
 				PhotosService: photosService,
 				FileTracker:   cli.FileTracker,
 				Logger:        cli.Logger,
+				Broadcaster:   cli.Progress,
+				Limiter:       limiters.For(config.Account),
 
 				Path:            i.Path,
-				AlbumName:       i.AlbumName,
+				AlbumName:       albumName,
 				DeleteOnSuccess: config.DeleteAfterUpload,
 			})
 		}
 	}
 
+	var bars *progressPool
+	if !cmd.NoProgress && !cmd.Silent {
+		bars = newProgressPool(cli.Progress, totalItems)
+		if err := bars.Start(); err != nil {
+			cli.Logger.Infof("Could not start the progress bar, falling back to log-only output: %s", err)
+			bars.Stop()
+			bars = nil
+		}
+	}
+
 	// get responses from the enqueued jobs
 	var uploadedItems int
+	var quotaExceeded bool
 	for i := 0; i < totalItems; i++ {
 		r := <-uploadQueue.ChanJobResults()
 
+		if bars != nil {
+			bars.Increment()
+		}
+		if errors.Is(r.Err, ratelimit.ErrQuotaExceeded) {
+			quotaExceeded = true
+		}
 		if r.Err != nil {
 			cli.Logger.Failf("Error processing %s", r.ID)
 		} else {
@@ -152,180 +185,13 @@ func (cmd *PushCmd) Run(cobraCmd *cobra.Command, args []string) error {
 		}
 	}
 
-	cli.Logger.Infof("%d processed files: %d successfully, %d with errors", totalItems, uploadedItems, totalItems-uploadedItems)
-	return nil
-}
-
-func getAllPhotosFromAlbum(photosService *gphotos.Client, albumId string) ([]*photoslibrary.MediaItem, error) {
-	photos := make([]*photoslibrary.MediaItem, 0)
-
-	token := ""
-
-get_page:
-	search1, err := photosService.MediaItems.Search(&photoslibrary.SearchMediaItemsRequest{
-		AlbumId:   albumId,
-		PageToken: token,
-	}).Do()
-	if err != nil {
-		return nil, err
-	}
-	photos = append(photos, search1.MediaItems...)
-
-	if len(search1.NextPageToken) > 0 {
-		token = search1.NextPageToken
-		//fmt.Println(search1.NextPageToken)
-		goto get_page
-	}
-
-	return photos, nil
-}
-
-func addPhotosToAlbum(photosService *gphotos.Client, albumId string, mediaitems []string) error {
-	chunkSize := 50 //max number of items per request
-	for i := 0; i < len(mediaitems); i += chunkSize {
-		end := i + chunkSize
-		if end > len(mediaitems) {
-			end = len(mediaitems)
-		}
-		_, err := photosService.Albums.BatchAddMediaItems(albumId, &photoslibrary.AlbumBatchAddMediaItemsRequest{MediaItemIds: mediaitems[i:end]}).Do()
-		if err != nil {
-			return err
-		}
-	}
-	//fmt.Println("Added", len(mediaitems), "items")
-	return nil
-}
-
-// Returns the id of the "good album
-func dedupe(photosService *gphotos.Client, id1, id2 string) (string, error) {
-
-	a1, err := photosService.Albums.Get(id1).Do()
-	if err != nil {
-		return "", err
-	}
-	a2, err := photosService.Albums.Get(id2).Do()
-	if err != nil {
-		return "", err
-	}
-
-	photos1, err := getAllPhotosFromAlbum(photosService, id1)
-	if err != nil {
-		return "", err
-	}
-	photos2, err := getAllPhotosFromAlbum(photosService, id2)
-	if err != nil {
-		return "", err
-	}
-
-	fmt.Printf("Dedupe '%s' (%d) and '%s' (%d)\n", a1.Title, len(photos1), a2.Title, len(photos2))
-
-	var itemsToAdd []*photoslibrary.MediaItem
-	var albumToAddTo string
-	var albumToDelete string
-
-	// Change the smaller number of photos
-	if len(photos1) > len(photos2) {
-		albumToAddTo = id1
-		itemsToAdd = photos2
-		albumToDelete = a2.ProductUrl
-	} else {
-		albumToAddTo = id2
-		itemsToAdd = photos1
-		albumToDelete = a1.ProductUrl
-	}
-
-	var itemIdsToAdd []string
-	for _, x := range itemsToAdd {
-		itemIdsToAdd = append(itemIdsToAdd, x.Id)
-	}
-
-	err = addPhotosToAlbum(photosService, albumToAddTo, itemIdsToAdd)
-	if err != nil {
-		return "", err
-	}
-
-	fmt.Println("To delete: ", albumToDelete)
-
-	return albumToAddTo, nil
-}
-
-func (cmd *DedupeCmd) Run(cobraCmd *cobra.Command, args []string) error {
-	cfg, err := config.LoadConfigAndValidate(cmd.CfgDir)
-	if err != nil {
-		return fmt.Errorf("please review your configuration or run 'gphotos-uploader-cli init': file=%s, err=%s", cmd.CfgDir, err)
-	}
-
-	cli, err := app.Start(cfg)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		_ = cli.Stop()
-	}()
-
-	// get OAuth2 Configuration with our App credentials
-	oauth2Config := oauth2.Config{
-		ClientID:     cfg.APIAppCredentials.ClientID,
-		ClientSecret: cfg.APIAppCredentials.ClientSecret,
-		Endpoint:     photos.Endpoint,
-		Scopes:       photos.Scopes,
+	if bars != nil {
+		bars.Stop()
 	}
 
-	uploadQueue := worker.NewJobQueue(cmd.NumberOfWorkers, cli.Logger)
-	uploadQueue.Start()
-	defer uploadQueue.Stop()
-	time.Sleep(1 * time.Second) // sleeps to avoid log messages colliding with output.
-
-	ctx := context.Background()
-	for _, config := range cfg.Jobs {
-		c, err := cli.NewOAuth2Client(ctx, oauth2Config, config.Account)
-		if err != nil {
-			return err
-		}
-
-		photosService, err := gphotos.NewClientWithResumableUploads(c, cli.UploadTracker)
-		if err != nil {
-			return err
-		}
-
-		token := ""
-
-		albums := make(map[string]string)
-
-		stop_after_first := false
-
-		parsed := 0
-
-	get_page:
-		resp, err := photosService.Albums.List().PageSize(50).PageToken(token).Do()
-		if err != nil {
-			return err
-		}
-
-		for _, album := range resp.Albums {
-			if dupe_id, exists := albums[album.Title]; exists {
-				album_to_keep, err := dedupe(photosService, dupe_id, album.Id)
-				if err != nil {
-					return err
-				}
-				albums[album.Title] = album_to_keep
-				if stop_after_first {
-					return nil
-				}
-			} else {
-				albums[album.Title] = album.Id
-			}
-			parsed += 1
-			//fmt.Println("Parsed:", parsed)
-		}
-
-		token = resp.NextPageToken
-		if len(token) > 0 {
-			//fmt.Println(token)
-			fmt.Println("Parsed", parsed, "albums")
-			goto get_page
-		}
+	cli.Logger.Infof("%d processed files: %d successfully, %d with errors", totalItems, uploadedItems, totalItems-uploadedItems)
+	if quotaExceeded {
+		return ratelimit.ErrQuotaExceeded
 	}
-
 	return nil
 }
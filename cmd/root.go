@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/gphotosuploader/gphotos-uploader-cli/cmd/flags"
+)
+
+// NewRootCmd assembles the gphotos-uploader-cli command tree. main calls Execute, not this
+// directly, so every subcommand's exit code goes through ExitCodeFor.
+func NewRootCmd() *cobra.Command {
+	globalFlags := &flags.GlobalFlags{}
+
+	rootCmd := &cobra.Command{
+		Use:   "gphotos-uploader-cli",
+		Short: "Upload files to Google Photos from the command line",
+	}
+
+	rootCmd.PersistentFlags().StringVar(&globalFlags.CfgDir, "config", "", "Config folder")
+
+	rootCmd.AddCommand(NewPushCmd(globalFlags))
+	rootCmd.AddCommand(NewPullCmd(globalFlags))
+	rootCmd.AddCommand(NewDedupeCmd(globalFlags))
+
+	return rootCmd
+}
+
+// Execute runs the command tree and exits the process with the code ExitCodeFor derives from
+// whatever error (if any) the selected subcommand's RunE returned, so a quota-exhausted run is
+// distinguishable from a generic failure without parsing log output.
+func Execute() {
+	err := NewRootCmd().Execute()
+	os.Exit(ExitCodeFor(err))
+}
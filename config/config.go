@@ -0,0 +1,92 @@
+// Package config loads and validates config.yaml, the per-account push/pull job definitions.
+package config
+
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+)
+
+// APIAppCredentials holds the OAuth2 app credentials used to authenticate every account.
+type APIAppCredentials struct {
+	ClientID     string `mapstructure:"client_id"`
+	ClientSecret string `mapstructure:"client_secret"`
+}
+
+// MakeAlbums is the legacy boolean toggle for album creation on push, superseded by JobConfig's
+// Layout field for jobs that set one.
+type MakeAlbums struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Use     string `mapstructure:"use"`
+}
+
+// JobConfig holds the configuration for a single `push` job: a local folder uploaded to a
+// Google account.
+type JobConfig struct {
+	Account      string `mapstructure:"account"`
+	SourceFolder string `mapstructure:"sourceFolder"`
+
+	MakeAlbums MakeAlbums `mapstructure:"makeAlbums"`
+
+	// Layout is a pattern template, e.g. "album/{album}/{year}/{month}/{filename}", used to
+	// derive the target album (and any date hint) from a file's path relative to
+	// SourceFolder. When set, it takes precedence over MakeAlbums.
+	Layout string `mapstructure:"layout"`
+
+	IncludePatterns []string `mapstructure:"includePatterns"`
+	ExcludePatterns []string `mapstructure:"excludePatterns"`
+	UploadVideos    bool     `mapstructure:"uploadVideos"`
+
+	DeleteAfterUpload bool `mapstructure:"deleteAfterUpload"`
+}
+
+// Config is the parsed content of config.yaml.
+type Config struct {
+	// ConfigDir is the directory config.yaml was loaded from, not itself read from the file.
+	ConfigDir string `mapstructure:"-"`
+
+	Debug bool `mapstructure:"debug"`
+
+	APIAppCredentials APIAppCredentials `mapstructure:"apiAppCredentials"`
+
+	Jobs []JobConfig `mapstructure:"jobs"`
+
+	// PullJobs configures the `pull` command's remote-to-local mirroring jobs, one per
+	// destination folder.
+	PullJobs []PullJob `mapstructure:"pullJobs"`
+
+	// RateLimit configures the per-account API rate limiting and concurrent-upload cap shared
+	// by push, pull and dedupe.
+	RateLimit RateLimit `mapstructure:"rateLimit"`
+}
+
+// LoadConfigAndValidate reads config.yaml from confDir, unmarshals it into a Config, and
+// compiles every job's Layout template so a typo is caught at load time rather than mid-run.
+func LoadConfigAndValidate(confDir string) (*Config, error) {
+	v := viper.New()
+	v.SetConfigName("config")
+	v.AddConfigPath(confDir)
+
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("could not read configuration: %s", err)
+	}
+
+	var cfg Config
+	if err := v.Unmarshal(&cfg); err != nil {
+		return nil, fmt.Errorf("could not parse configuration: %s", err)
+	}
+	cfg.ConfigDir = confDir
+
+	for i, job := range cfg.Jobs {
+		if err := ValidateLayout(fmt.Sprintf("jobs[%d].layout", i), job.Layout); err != nil {
+			return nil, err
+		}
+	}
+	for i, pullJob := range cfg.PullJobs {
+		if err := ValidateLayout(fmt.Sprintf("pullJobs[%d].layout", i), pullJob.Layout); err != nil {
+			return nil, err
+		}
+	}
+
+	return &cfg, nil
+}
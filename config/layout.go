@@ -0,0 +1,20 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/gphotosuploader/gphotos-uploader-cli/pattern"
+)
+
+// ValidateLayout compiles a job's `layout` template, returning an error that names the field it
+// came from if the template is malformed. It's called from LoadConfigAndValidate for every job
+// and pull job that sets Layout.
+func ValidateLayout(field, layout string) error {
+	if layout == "" {
+		return nil
+	}
+	if _, err := pattern.Compile(layout); err != nil {
+		return fmt.Errorf("%s: %s", field, err)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package config
+
+import "time"
+
+// PullJob holds the configuration for a single `pull` job, mirroring the
+// local-to-remote options of JobConfig for the remote-to-local direction.
+type PullJob struct {
+	// Account is the Google account this job pulls media from.
+	Account string `mapstructure:"account"`
+
+	// DestinationFolder is the local folder where downloaded originals are stored.
+	DestinationFolder string `mapstructure:"destinationFolder"`
+
+	// Album restricts the pull to a single album, matched by title. Empty means "all media".
+	Album string `mapstructure:"album"`
+
+	// DateFrom and DateTo restrict the pull to media created within the range. Zero values mean unbounded.
+	DateFrom time.Time `mapstructure:"dateFrom"`
+	DateTo   time.Time `mapstructure:"dateTo"`
+
+	IncludePatterns []string `mapstructure:"includePatterns"`
+	ExcludePatterns []string `mapstructure:"excludePatterns"`
+
+	// Layout is a pattern template, e.g. "album/{album}/{year}/{month}/{filename}", deciding
+	// where downloaded items are placed. Empty means FlatLayout decides: flat when set, otherwise
+	// the default AlbumTitle/YYYY/MM/ layout.
+	Layout string `mapstructure:"layout"`
+
+	// FlatLayout stores every file directly under DestinationFolder instead of AlbumTitle/YYYY/MM/.
+	// Ignored when Layout is set.
+	FlatLayout bool `mapstructure:"flatLayout"`
+}
@@ -0,0 +1,27 @@
+package config
+
+import "github.com/gphotosuploader/gphotos-uploader-cli/ratelimit"
+
+// RateLimit holds the per-account API rate limiting settings read from config.yaml. Zero values
+// fall back to ratelimit.DefaultConfig via RateLimitOrDefault.
+type RateLimit struct {
+	RequestsPerSecond    float64 `mapstructure:"requestsPerSecond"`
+	Burst                int     `mapstructure:"burst"`
+	MaxConcurrentUploads int     `mapstructure:"maxConcurrentUploads"`
+}
+
+// RateLimitOrDefault converts a RateLimit read from config.yaml into a ratelimit.Config,
+// substituting conservative defaults for any field left unset.
+func (r RateLimit) RateLimitOrDefault() ratelimit.Config {
+	cfg := ratelimit.DefaultConfig()
+	if r.RequestsPerSecond > 0 {
+		cfg.RequestsPerSecond = r.RequestsPerSecond
+	}
+	if r.Burst > 0 {
+		cfg.Burst = r.Burst
+	}
+	if r.MaxConcurrentUploads > 0 {
+		cfg.MaxConcurrentUploads = r.MaxConcurrentUploads
+	}
+	return cfg
+}
@@ -0,0 +1,73 @@
+package dedupe
+
+import (
+	"encoding/json"
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// cleanupBucket is the BoltDB bucket used to track albums merged away by `dedupe`, which the
+// Library API cannot delete, so the user still has to remove them in the web UI.
+var cleanupBucket = []byte("DedupeCleanupQueue")
+
+// CleanupEntry is a single album still awaiting manual deletion.
+type CleanupEntry struct {
+	Account    string `json:"account"`
+	AlbumID    string `json:"albumId"`
+	Title      string `json:"title"`
+	ProductUrl string `json:"productUrl"`
+}
+
+// CleanupQueue persists the albums a merge emptied, so the user can track and finish the manual
+// deletion step the Library API can't perform itself.
+type CleanupQueue struct {
+	db *bolt.DB
+}
+
+// NewCleanupQueue returns a CleanupQueue backed by the given BoltDB database, creating its
+// bucket if needed.
+func NewCleanupQueue(db *bolt.DB) (*CleanupQueue, error) {
+	err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(cleanupBucket)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not create cleanup queue bucket: %s", err)
+	}
+	return &CleanupQueue{db: db}, nil
+}
+
+// Add queues entry for manual deletion, keyed by its AlbumID.
+func (q *CleanupQueue) Add(entry CleanupEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return q.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(cleanupBucket).Put([]byte(entry.AlbumID), data)
+	})
+}
+
+// Remove drops albumId from the queue, e.g. once the user has deleted it manually.
+func (q *CleanupQueue) Remove(albumID string) error {
+	return q.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(cleanupBucket).Delete([]byte(albumID))
+	})
+}
+
+// List returns every album still queued for manual deletion.
+func (q *CleanupQueue) List() ([]CleanupEntry, error) {
+	var entries []CleanupEntry
+	err := q.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(cleanupBucket).ForEach(func(_, data []byte) error {
+			var entry CleanupEntry
+			if err := json.Unmarshal(data, &entry); err != nil {
+				return err
+			}
+			entries = append(entries, entry)
+			return nil
+		})
+	})
+	return entries, err
+}
@@ -0,0 +1,70 @@
+package dedupe
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	"math/bits"
+
+	"golang.org/x/image/draw"
+)
+
+// hashSize is the side, in pixels, of the grayscale thumbnail a dHash is computed from.
+const hashSize = 8
+
+// Fingerprint is the pair of hashes computed for a media item's preview image.
+type Fingerprint struct {
+	SHA256 string
+	PHash  uint64
+	Width  int
+	Height int
+}
+
+// Compute decodes a JPEG preview and returns its SHA-256 and a 64-bit difference hash (dHash):
+// the preview is downscaled to a 9x8 grayscale thumbnail and the hash records, for every pixel,
+// whether it's brighter than its right neighbour. Visually-identical images produce identical or
+// near-identical hashes even when re-encoded, which a pure SHA-256 of the bytes would not catch.
+func Compute(previewJPEG []byte) (Fingerprint, error) {
+	sum := sha256.Sum256(previewJPEG)
+
+	img, _, err := image.Decode(bytes.NewReader(previewJPEG))
+	if err != nil {
+		return Fingerprint{}, fmt.Errorf("could not decode preview image: %s", err)
+	}
+
+	phash := dHash(img)
+	bounds := img.Bounds()
+
+	return Fingerprint{
+		SHA256: hex.EncodeToString(sum[:]),
+		PHash:  phash,
+		Width:  bounds.Dx(),
+		Height: bounds.Dy(),
+	}, nil
+}
+
+// HammingDistance returns the number of differing bits between two fingerprints' dHashes.
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+func dHash(img image.Image) uint64 {
+	gray := image.NewGray(image.Rect(0, 0, hashSize+1, hashSize))
+	draw.ApproxBiLinear.Scale(gray, gray.Bounds(), img, img.Bounds(), draw.Over, nil)
+
+	var hash uint64
+	for y := 0; y < hashSize; y++ {
+		for x := 0; x < hashSize; x++ {
+			left := gray.GrayAt(x, y).Y
+			right := gray.GrayAt(x+1, y).Y
+			hash <<= 1
+			if left > right {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
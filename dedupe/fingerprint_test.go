@@ -0,0 +1,82 @@
+package dedupe
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+)
+
+func solidJPEG(t *testing.T, c color.Gray, w, h int) []byte {
+	t.Helper()
+
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetGray(x, y, c)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("could not encode test JPEG: %s", err)
+	}
+	return buf.Bytes()
+}
+
+func TestCompute_IdenticalImagesProduceIdenticalFingerprints(t *testing.T) {
+	a := solidJPEG(t, color.Gray{Y: 128}, 64, 64)
+	b := solidJPEG(t, color.Gray{Y: 128}, 64, 64)
+
+	fa, err := Compute(a)
+	if err != nil {
+		t.Fatalf("Compute(a): %s", err)
+	}
+	fb, err := Compute(b)
+	if err != nil {
+		t.Fatalf("Compute(b): %s", err)
+	}
+
+	if fa.SHA256 != fb.SHA256 {
+		t.Fatal("expected identical bytes to produce identical SHA256")
+	}
+	if HammingDistance(fa.PHash, fb.PHash) != 0 {
+		t.Fatalf("expected identical images to have a Hamming distance of 0, got %d", HammingDistance(fa.PHash, fb.PHash))
+	}
+}
+
+func TestCompute_RecordsDimensions(t *testing.T) {
+	jpegBytes := solidJPEG(t, color.Gray{Y: 200}, 32, 16)
+
+	fp, err := Compute(jpegBytes)
+	if err != nil {
+		t.Fatalf("Compute: %s", err)
+	}
+	if fp.Width != 32 || fp.Height != 16 {
+		t.Fatalf("got %dx%d, want 32x16", fp.Width, fp.Height)
+	}
+}
+
+func TestCompute_RejectsUndecodableInput(t *testing.T) {
+	if _, err := Compute([]byte("not a jpeg")); err == nil {
+		t.Fatal("expected an error for undecodable input")
+	}
+}
+
+func TestHammingDistance(t *testing.T) {
+	tests := []struct {
+		a, b uint64
+		want int
+	}{
+		{0, 0, 0},
+		{0, 1, 1},
+		{0b1111, 0b0000, 4},
+		{^uint64(0), 0, 64},
+	}
+	for _, tt := range tests {
+		if got := HammingDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("HammingDistance(%b, %b) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
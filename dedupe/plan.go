@@ -0,0 +1,70 @@
+package dedupe
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// AlbumPair is the merge decision for a single pair of same-titled albums: which one is kept,
+// which one would be emptied into it, and how many items that involves.
+type AlbumPair struct {
+	KeptAlbumID       string `json:"keptAlbumId"`
+	KeptTitle         string `json:"keptTitle"`
+	KeptProductUrl    string `json:"keptProductUrl"`
+	KeptItemCount     int    `json:"keptItemCount"`
+	DeletedAlbumID    string `json:"deletedAlbumId"`
+	DeletedTitle      string `json:"deletedTitle"`
+	DeletedProductUrl string `json:"deletedProductUrl"`
+	DeletedItemCount  int    `json:"deletedItemCount"`
+}
+
+// MergePlan is the full set of album merges a `dedupe` run would perform.
+type MergePlan struct {
+	Strategy KeeperStrategy `json:"strategy"`
+	Pairs    []AlbumPair    `json:"pairs"`
+}
+
+// WritePlanJSON writes the plan as indented JSON.
+func WritePlanJSON(w io.Writer, plan MergePlan) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(plan)
+}
+
+// WritePlanText writes a human-readable table of the plan.
+func WritePlanText(w io.Writer, plan MergePlan) error {
+	fmt.Fprintf(w, "Album merge plan (keeper strategy: %s)\n", plan.Strategy)
+	if len(plan.Pairs) == 0 {
+		fmt.Fprintln(w, "No duplicate albums found.")
+		return nil
+	}
+
+	for i, pair := range plan.Pairs {
+		fmt.Fprintf(w, "\nPair %d: %q\n", i+1, pair.KeptTitle)
+		fmt.Fprintf(w, "  keep:   %s (%d items)\n", pair.KeptProductUrl, pair.KeptItemCount)
+		fmt.Fprintf(w, "  delete: %s (%d items, to be merged into the kept album then deleted manually)\n", pair.DeletedProductUrl, pair.DeletedItemCount)
+	}
+	return nil
+}
+
+// WritePlanJSONFile writes the plan as JSON to path.
+func WritePlanJSONFile(path string, plan MergePlan) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return WritePlanJSON(f, plan)
+}
+
+// WritePlanTextFile writes the human-readable plan to path.
+func WritePlanTextFile(path string, plan MergePlan) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return WritePlanText(f, plan)
+}
@@ -0,0 +1,158 @@
+package dedupe
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// DefaultHammingThreshold is the maximum dHash Hamming distance for two items to be
+// considered duplicates of each other.
+const DefaultHammingThreshold = 5
+
+// MediaRef identifies a single media item within a Cluster report.
+type MediaRef struct {
+	MediaItemID  string `json:"mediaItemId"`
+	ProductUrl   string `json:"productUrl"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	CreationTime string `json:"creationTime"`
+}
+
+// Cluster is a group of media items considered duplicates of each other. Keeper is the item the
+// keeper-selection strategy chose to keep (largest resolution, earliest creationTime); the rest
+// are the ones the user should open and delete manually, since the Library API cannot do it.
+type Cluster struct {
+	Keeper     MediaRef   `json:"keeper"`
+	Duplicates []MediaRef `json:"duplicates"`
+}
+
+// Report is the full set of duplicate clusters found within an album.
+type Report struct {
+	AlbumTitle string    `json:"albumTitle"`
+	Clusters   []Cluster `json:"clusters"`
+}
+
+// item pairs a MediaRef with the fingerprint used to cluster it.
+type item struct {
+	ref         MediaRef
+	fingerprint Fingerprint
+}
+
+// FindClusters groups items whose dHash Hamming distance is within threshold, and within each
+// cluster picks the keeper: largest resolution first, earliest creationTime to break ties.
+func FindClusters(refs []MediaRef, fingerprints map[string]Fingerprint, threshold int) []Cluster {
+	items := make([]item, 0, len(refs))
+	for _, ref := range refs {
+		if fp, ok := fingerprints[ref.MediaItemID]; ok {
+			items = append(items, item{ref: ref, fingerprint: fp})
+		}
+	}
+
+	seen := make(map[int]bool)
+	var clusters []Cluster
+
+	for i := range items {
+		if seen[i] {
+			continue
+		}
+		group := []item{items[i]}
+		seen[i] = true
+
+		for j := i + 1; j < len(items); j++ {
+			if seen[j] {
+				continue
+			}
+			if HammingDistance(items[i].fingerprint.PHash, items[j].fingerprint.PHash) <= threshold {
+				group = append(group, items[j])
+				seen[j] = true
+			}
+		}
+
+		if len(group) < 2 {
+			continue
+		}
+		clusters = append(clusters, newCluster(group))
+	}
+
+	return clusters
+}
+
+func newCluster(group []item) Cluster {
+	keeperIdx := 0
+	for i, it := range group[1:] {
+		if isBetterKeeper(it, group[keeperIdx]) {
+			keeperIdx = i + 1
+		}
+	}
+
+	var duplicates []MediaRef
+	for i, it := range group {
+		if i != keeperIdx {
+			duplicates = append(duplicates, it.ref)
+		}
+	}
+
+	return Cluster{Keeper: group[keeperIdx].ref, Duplicates: duplicates}
+}
+
+func isBetterKeeper(candidate, current item) bool {
+	candidateArea := candidate.ref.Width * candidate.ref.Height
+	currentArea := current.ref.Width * current.ref.Height
+	if candidateArea != currentArea {
+		return candidateArea > currentArea
+	}
+
+	candidateTime, errC := time.Parse(time.RFC3339, candidate.ref.CreationTime)
+	currentTime, errR := time.Parse(time.RFC3339, current.ref.CreationTime)
+	if errC != nil || errR != nil {
+		return false
+	}
+	return candidateTime.Before(currentTime)
+}
+
+// WriteJSON writes the report as indented JSON.
+func WriteJSON(w io.Writer, report Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// WriteText writes a human-readable table of the report's clusters.
+func WriteText(w io.Writer, report Report) error {
+	fmt.Fprintf(w, "Duplicate media report for album %q\n", report.AlbumTitle)
+	if len(report.Clusters) == 0 {
+		fmt.Fprintln(w, "No duplicates found.")
+		return nil
+	}
+
+	for i, cluster := range report.Clusters {
+		fmt.Fprintf(w, "\nCluster %d: keep %s (%dx%d, %s)\n", i+1, cluster.Keeper.ProductUrl, cluster.Keeper.Width, cluster.Keeper.Height, cluster.Keeper.CreationTime)
+		for _, dup := range cluster.Duplicates {
+			fmt.Fprintf(w, "  delete: %s (%dx%d, %s)\n", dup.ProductUrl, dup.Width, dup.Height, dup.CreationTime)
+		}
+	}
+	return nil
+}
+
+// WriteJSONFile writes the report as JSON to path.
+func WriteJSONFile(path string, report Report) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return WriteJSON(f, report)
+}
+
+// WriteTextFile writes the human-readable report to path.
+func WriteTextFile(path string, report Report) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return WriteText(f, report)
+}
@@ -0,0 +1,67 @@
+package dedupe
+
+import "testing"
+
+func TestFindClusters_GroupsWithinThreshold(t *testing.T) {
+	refs := []MediaRef{
+		{MediaItemID: "a", Width: 100, Height: 100, CreationTime: "2024-01-01T00:00:00Z"},
+		{MediaItemID: "b", Width: 200, Height: 200, CreationTime: "2024-01-02T00:00:00Z"},
+		{MediaItemID: "c", Width: 50, Height: 50, CreationTime: "2024-01-03T00:00:00Z"},
+	}
+	fingerprints := map[string]Fingerprint{
+		"a": {PHash: 0b0000},
+		"b": {PHash: 0b0001}, // Hamming distance 1 from a, within threshold.
+		"c": {PHash: 0b1111}, // Hamming distance 4 from a, outside threshold.
+	}
+
+	clusters := FindClusters(refs, fingerprints, 2)
+
+	if len(clusters) != 1 {
+		t.Fatalf("got %d clusters, want 1", len(clusters))
+	}
+	if len(clusters[0].Duplicates) != 1 {
+		t.Fatalf("got %d duplicates, want 1", len(clusters[0].Duplicates))
+	}
+	// b has the larger resolution, so it should be the keeper.
+	if clusters[0].Keeper.MediaItemID != "b" {
+		t.Fatalf("keeper = %q, want %q", clusters[0].Keeper.MediaItemID, "b")
+	}
+	if clusters[0].Duplicates[0].MediaItemID != "a" {
+		t.Fatalf("duplicate = %q, want %q", clusters[0].Duplicates[0].MediaItemID, "a")
+	}
+}
+
+func TestFindClusters_NoDuplicatesReturnsNoClusters(t *testing.T) {
+	refs := []MediaRef{
+		{MediaItemID: "a"},
+		{MediaItemID: "b"},
+	}
+	fingerprints := map[string]Fingerprint{
+		"a": {PHash: 0b0000},
+		"b": {PHash: 0b1111},
+	}
+
+	clusters := FindClusters(refs, fingerprints, 1)
+	if len(clusters) != 0 {
+		t.Fatalf("got %d clusters, want 0", len(clusters))
+	}
+}
+
+func TestFindClusters_TiesBrokenByEarliestCreationTime(t *testing.T) {
+	refs := []MediaRef{
+		{MediaItemID: "a", Width: 100, Height: 100, CreationTime: "2024-06-01T00:00:00Z"},
+		{MediaItemID: "b", Width: 100, Height: 100, CreationTime: "2024-01-01T00:00:00Z"},
+	}
+	fingerprints := map[string]Fingerprint{
+		"a": {PHash: 0},
+		"b": {PHash: 0},
+	}
+
+	clusters := FindClusters(refs, fingerprints, 0)
+	if len(clusters) != 1 {
+		t.Fatalf("got %d clusters, want 1", len(clusters))
+	}
+	if clusters[0].Keeper.MediaItemID != "b" {
+		t.Fatalf("keeper = %q, want %q (earliest creationTime)", clusters[0].Keeper.MediaItemID, "b")
+	}
+}
@@ -0,0 +1,64 @@
+package dedupe
+
+import (
+	"encoding/json"
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// fingerprintsBucket is the BoltDB bucket, under the app's existing store, used to persist
+// previously computed fingerprints so that repeated `dedupe --mode=media` runs are incremental.
+var fingerprintsBucket = []byte("MediaFingerprints")
+
+// Record is what Store persists for every media item whose fingerprint has been computed.
+type Record struct {
+	Fingerprint
+	CreationTime string `json:"creationTime"`
+}
+
+// Store persists media item fingerprints in a BoltDB bucket.
+type Store struct {
+	db *bolt.DB
+}
+
+// NewStore returns a Store backed by the given BoltDB database, creating its bucket if needed.
+func NewStore(db *bolt.DB) (*Store, error) {
+	err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(fingerprintsBucket)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not create fingerprints bucket: %s", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Get returns the previously persisted Record for mediaItemID, if any.
+func (s *Store) Get(mediaItemID string) (Record, bool) {
+	var record Record
+	var found bool
+	_ = s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(fingerprintsBucket).Get([]byte(mediaItemID))
+		if data == nil {
+			return nil
+		}
+		if err := json.Unmarshal(data, &record); err != nil {
+			return nil
+		}
+		found = true
+		return nil
+	})
+	return record, found
+}
+
+// Put persists the Record for mediaItemID.
+func (s *Store) Put(mediaItemID string, record Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(fingerprintsBucket).Put([]byte(mediaItemID), data)
+	})
+}
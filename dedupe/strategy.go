@@ -0,0 +1,90 @@
+package dedupe
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gphotosuploader/googlemirror/api/photoslibrary/v1"
+)
+
+// KeeperStrategy decides, for a pair of same-titled albums, which one survives the merge.
+type KeeperStrategy string
+
+const (
+	// MostItems keeps the album with more media items; on a tie it keeps the album encountered
+	// first.
+	MostItems KeeperStrategy = "most-items"
+	// Oldest keeps the album whose earliest media item was created first.
+	Oldest KeeperStrategy = "oldest"
+	// Newest keeps the album whose most recent media item was created last.
+	Newest KeeperStrategy = "newest"
+	// TitleExactMatch keeps the album encountered first when albums are listed, on the
+	// assumption that Google Photos appends a suffix to the title of later duplicates.
+	TitleExactMatch KeeperStrategy = "title-exact-match"
+)
+
+// ParseKeeperStrategy validates a --keeper-strategy flag value.
+func ParseKeeperStrategy(s string) (KeeperStrategy, error) {
+	switch KeeperStrategy(s) {
+	case MostItems, Oldest, Newest, TitleExactMatch:
+		return KeeperStrategy(s), nil
+	default:
+		return "", fmt.Errorf("unknown keeper strategy %q: must be one of %s, %s, %s, %s", s, MostItems, Oldest, Newest, TitleExactMatch)
+	}
+}
+
+// ChooseKeeper picks which of two same-titled albums to keep, per strategy. a1/photos1 is the
+// album encountered first when listing albums; a2/photos2 is the later duplicate.
+func ChooseKeeper(strategy KeeperStrategy, a1, a2 *photoslibrary.Album, photos1, photos2 []*photoslibrary.MediaItem) (keep *photoslibrary.Album, discard *photoslibrary.Album, keepItems, discardItems []*photoslibrary.MediaItem) {
+	switch strategy {
+	case Oldest:
+		if earliestCreationTime(photos1).Before(earliestCreationTime(photos2)) {
+			return a1, a2, photos1, photos2
+		}
+		return a2, a1, photos2, photos1
+	case Newest:
+		if latestCreationTime(photos1).After(latestCreationTime(photos2)) {
+			return a1, a2, photos1, photos2
+		}
+		return a2, a1, photos2, photos1
+	case TitleExactMatch:
+		return a1, a2, photos1, photos2
+	case MostItems:
+		fallthrough
+	default:
+		if len(photos1) >= len(photos2) {
+			return a1, a2, photos1, photos2
+		}
+		return a2, a1, photos2, photos1
+	}
+}
+
+func earliestCreationTime(items []*photoslibrary.MediaItem) time.Time {
+	var best time.Time
+	var seen bool
+	for _, mi := range items {
+		t, err := time.Parse(time.RFC3339, mi.MediaMetadata.CreationTime)
+		if err != nil {
+			continue
+		}
+		if !seen || t.Before(best) {
+			best = t
+			seen = true
+		}
+	}
+	return best
+}
+
+func latestCreationTime(items []*photoslibrary.MediaItem) time.Time {
+	var best time.Time
+	for _, mi := range items {
+		t, err := time.Parse(time.RFC3339, mi.MediaMetadata.CreationTime)
+		if err != nil {
+			continue
+		}
+		if t.After(best) {
+			best = t
+		}
+	}
+	return best
+}
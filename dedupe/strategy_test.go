@@ -0,0 +1,84 @@
+package dedupe
+
+import (
+	"testing"
+
+	"github.com/gphotosuploader/googlemirror/api/photoslibrary/v1"
+)
+
+func TestParseKeeperStrategy(t *testing.T) {
+	valid := []string{"most-items", "oldest", "newest", "title-exact-match"}
+	for _, s := range valid {
+		if _, err := ParseKeeperStrategy(s); err != nil {
+			t.Errorf("ParseKeeperStrategy(%q) = %v, want nil error", s, err)
+		}
+	}
+
+	if _, err := ParseKeeperStrategy("bogus"); err == nil {
+		t.Error("ParseKeeperStrategy(\"bogus\") = nil error, want an error")
+	}
+}
+
+func mediaItemAt(creationTime string) *photoslibrary.MediaItem {
+	return &photoslibrary.MediaItem{
+		MediaMetadata: &photoslibrary.MediaMetadata{CreationTime: creationTime},
+	}
+}
+
+func TestChooseKeeper_MostItems(t *testing.T) {
+	a1 := &photoslibrary.Album{Id: "1"}
+	a2 := &photoslibrary.Album{Id: "2"}
+	photos1 := []*photoslibrary.MediaItem{mediaItemAt("2024-01-01T00:00:00Z")}
+	photos2 := []*photoslibrary.MediaItem{mediaItemAt("2024-01-01T00:00:00Z"), mediaItemAt("2024-01-02T00:00:00Z")}
+
+	keep, discard, _, _ := ChooseKeeper(MostItems, a1, a2, photos1, photos2)
+	if keep.Id != "2" || discard.Id != "1" {
+		t.Fatalf("keep=%s discard=%s, want keep=2 discard=1", keep.Id, discard.Id)
+	}
+}
+
+func TestChooseKeeper_Oldest(t *testing.T) {
+	a1 := &photoslibrary.Album{Id: "1"}
+	a2 := &photoslibrary.Album{Id: "2"}
+	photos1 := []*photoslibrary.MediaItem{mediaItemAt("2024-06-01T00:00:00Z")}
+	photos2 := []*photoslibrary.MediaItem{mediaItemAt("2024-01-01T00:00:00Z")}
+
+	keep, discard, _, _ := ChooseKeeper(Oldest, a1, a2, photos1, photos2)
+	if keep.Id != "2" || discard.Id != "1" {
+		t.Fatalf("keep=%s discard=%s, want keep=2 discard=1", keep.Id, discard.Id)
+	}
+}
+
+func TestChooseKeeper_Newest(t *testing.T) {
+	a1 := &photoslibrary.Album{Id: "1"}
+	a2 := &photoslibrary.Album{Id: "2"}
+	photos1 := []*photoslibrary.MediaItem{mediaItemAt("2024-06-01T00:00:00Z")}
+	photos2 := []*photoslibrary.MediaItem{mediaItemAt("2024-01-01T00:00:00Z")}
+
+	keep, discard, _, _ := ChooseKeeper(Newest, a1, a2, photos1, photos2)
+	if keep.Id != "1" || discard.Id != "2" {
+		t.Fatalf("keep=%s discard=%s, want keep=1 discard=2", keep.Id, discard.Id)
+	}
+}
+
+func TestChooseKeeper_OldestIgnoresUnparseableLeadingItem(t *testing.T) {
+	a1 := &photoslibrary.Album{Id: "1"}
+	a2 := &photoslibrary.Album{Id: "2"}
+	photos1 := []*photoslibrary.MediaItem{mediaItemAt(""), mediaItemAt("2024-06-01T00:00:00Z")}
+	photos2 := []*photoslibrary.MediaItem{mediaItemAt("2024-01-01T00:00:00Z")}
+
+	keep, discard, _, _ := ChooseKeeper(Oldest, a1, a2, photos1, photos2)
+	if keep.Id != "2" || discard.Id != "1" {
+		t.Fatalf("keep=%s discard=%s, want keep=2 discard=1", keep.Id, discard.Id)
+	}
+}
+
+func TestChooseKeeper_TitleExactMatchKeepsFirstEncountered(t *testing.T) {
+	a1 := &photoslibrary.Album{Id: "1"}
+	a2 := &photoslibrary.Album{Id: "2"}
+
+	keep, discard, _, _ := ChooseKeeper(TitleExactMatch, a1, a2, nil, nil)
+	if keep.Id != "1" || discard.Id != "2" {
+		t.Fatalf("keep=%s discard=%s, want keep=1 discard=2", keep.Id, discard.Id)
+	}
+}
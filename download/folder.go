@@ -0,0 +1,233 @@
+package download
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	gphotos "github.com/gphotosuploader/google-photos-api-client-go/lib-gphotos"
+	"github.com/gphotosuploader/googlemirror/api/photoslibrary/v1"
+
+	"github.com/gphotosuploader/gphotos-uploader-cli/log"
+	"github.com/gphotosuploader/gphotos-uploader-cli/pattern"
+	"github.com/gphotosuploader/gphotos-uploader-cli/ratelimit"
+	"github.com/gphotosuploader/gphotos-uploader-cli/upload"
+)
+
+// DownloadItem is a single media item selected by FolderJob, ready to be handed to a worker.
+type DownloadItem struct {
+	MediaItemID  string
+	BaseURL      string
+	Filename     string
+	CreationTime string
+	AlbumTitle   string
+	IsVideo      bool
+	DestPath     string
+}
+
+// FolderJob walks a remote Google Photos library (optionally restricted to a single album and/or
+// a date range) and decides which media items should be downloaded to DestinationFolder.
+// It's the pull-side counterpart of upload.UploadFolderJob.
+type FolderJob struct {
+	Tracker *Tracker
+
+	// Logger receives a notice when DateFrom/DateTo is ignored because Album is also set; nil
+	// disables the notice.
+	Logger log.Interface
+
+	DestinationFolder string
+	Album             string
+	DateFrom          time.Time
+	DateTo            time.Time
+	FlatLayout        bool
+
+	// Layout, when set, overrides FlatLayout and the AlbumTitle/YYYY/MM/ default with a
+	// compiled pattern.Pattern rendered once per media item.
+	Layout *pattern.Pattern
+
+	Filter *upload.Filter
+
+	// Limiter, when set, gates every Library API call this job makes.
+	Limiter *ratelimit.Limiter
+}
+
+// Plan lists the media items that should be downloaded and resolves, for each one, the local
+// path it should be written to. Items already recorded in Tracker are skipped. ctx cancels
+// in-flight API calls, e.g. on Ctrl-C.
+func (j *FolderJob) Plan(ctx context.Context, photosService *gphotos.Client) ([]DownloadItem, error) {
+	var albumID string
+	if j.Album != "" {
+		id, err := findAlbumIDByTitle(ctx, photosService, j.Limiter, j.Album)
+		if err != nil {
+			return nil, err
+		}
+		albumID = id
+
+		// The Library API rejects a request that combines albumId with any other filter, so a
+		// configured date range would otherwise be silently dropped.
+		if j.Logger != nil && (!j.DateFrom.IsZero() || !j.DateTo.IsZero()) {
+			j.Logger.Infof("Ignoring dateFrom/dateTo for album %q: the Library API doesn't support combining an album with a date filter.", j.Album)
+		}
+	}
+
+	var items []DownloadItem
+	seenPaths := make(map[string]bool)
+	token := ""
+
+get_page:
+	req := &photoslibrary.SearchMediaItemsRequest{
+		AlbumId:   albumID,
+		PageSize:  100,
+		PageToken: token,
+	}
+	if albumID == "" && (!j.DateFrom.IsZero() || !j.DateTo.IsZero()) {
+		req.Filters = dateRangeFilter(j.DateFrom, j.DateTo)
+	}
+
+	if err := j.Limiter.WaitForRequest(ctx); err != nil {
+		return nil, err
+	}
+	resp, err := photosService.MediaItems.Search(req).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("could not list media items: %s", err)
+	}
+
+	for _, mi := range resp.MediaItems {
+		if !j.Filter.IsFilenameIncluded(mi.Filename) {
+			continue
+		}
+		if j.Tracker.IsTracked(mi.Id, mi.MediaMetadata.CreationTime) {
+			continue
+		}
+
+		destPath := j.destPathFor(mi)
+		if seenPaths[destPath] {
+			destPath = dedupePath(destPath, mi.Id)
+		}
+		seenPaths[destPath] = true
+
+		items = append(items, DownloadItem{
+			MediaItemID:  mi.Id,
+			BaseURL:      mi.BaseUrl,
+			Filename:     mi.Filename,
+			CreationTime: mi.MediaMetadata.CreationTime,
+			AlbumTitle:   j.Album,
+			IsVideo:      mi.MediaMetadata.Video != nil,
+			DestPath:     destPath,
+		})
+	}
+
+	if len(resp.NextPageToken) > 0 {
+		token = resp.NextPageToken
+		goto get_page
+	}
+
+	return items, nil
+}
+
+// destPathFor resolves where a media item should be written, honouring Layout and FlatLayout.
+func (j *FolderJob) destPathFor(mi *photoslibrary.MediaItem) string {
+	album := j.Album
+	if album == "" {
+		album = "All"
+	}
+
+	year, month, day := "unknown-date", "unknown-date", "unknown-date"
+	if t, err := time.Parse(time.RFC3339, mi.MediaMetadata.CreationTime); err == nil {
+		year = fmt.Sprintf("%04d", t.Year())
+		month = fmt.Sprintf("%02d", t.Month())
+		day = fmt.Sprintf("%02d", t.Day())
+	}
+
+	if j.Layout != nil {
+		rendered := j.Layout.Render(pattern.Fields{
+			Album:    album,
+			Year:     year,
+			Month:    month,
+			Day:      day,
+			Filename: mi.Filename,
+			Ext:      strings.TrimPrefix(filepath.Ext(mi.Filename), "."),
+		})
+		return filepath.Join(j.DestinationFolder, rendered)
+	}
+
+	if j.FlatLayout {
+		return filepath.Join(j.DestinationFolder, mi.Filename)
+	}
+
+	return filepath.Join(j.DestinationFolder, album, year, month, mi.Filename)
+}
+
+// dedupePath disambiguates a DestPath collision between two distinct media items (e.g. a phone
+// that restarts its filename counter) by inserting mediaItemID before the extension, so the
+// second item doesn't overwrite the first on disk.
+func dedupePath(path, mediaItemID string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "-" + mediaItemID + ext
+}
+
+// OriginalURL returns the download variant of baseUrl needed to fetch the original bytes: `=dv`
+// for videos, since `=d` on a video only returns its poster frame, and `=d` for everything else.
+func OriginalURL(baseURL string, isVideo bool) string {
+	if isVideo {
+		return baseURL + "=dv"
+	}
+	return baseURL + "=d"
+}
+
+func findAlbumIDByTitle(ctx context.Context, photosService *gphotos.Client, limiter *ratelimit.Limiter, title string) (string, error) {
+	token := ""
+
+get_page:
+	if err := limiter.WaitForRequest(ctx); err != nil {
+		return "", err
+	}
+	resp, err := photosService.Albums.List().PageSize(50).PageToken(token).Context(ctx).Do()
+	if err != nil {
+		return "", err
+	}
+
+	for _, album := range resp.Albums {
+		if strings.EqualFold(album.Title, title) {
+			return album.Id, nil
+		}
+	}
+
+	if len(resp.NextPageToken) > 0 {
+		token = resp.NextPageToken
+		goto get_page
+	}
+
+	return "", fmt.Errorf("no album found with title %q", title)
+}
+
+// dateRangeFilter builds a Filters restricting results to [from, to]. It's only called when at
+// least one bound is set (see Plan); a zero from/to means "unbounded on that side", so a missing
+// bound is filled with a sane default instead of sending the Library API an empty DateFilter,
+// which it rejects with INVALID_ARGUMENT.
+func dateRangeFilter(from, to time.Time) *photoslibrary.Filters {
+	if from.IsZero() {
+		from = time.Unix(0, 0).UTC()
+	}
+	if to.IsZero() {
+		to = time.Now()
+	}
+	return &photoslibrary.Filters{
+		DateFilter: &photoslibrary.DateFilter{
+			Ranges: []*photoslibrary.DateRange{rangeOf(from, to)},
+		},
+	}
+}
+
+func rangeOf(from, to time.Time) *photoslibrary.DateRange {
+	return &photoslibrary.DateRange{
+		StartDate: dateOf(from),
+		EndDate:   dateOf(to),
+	}
+}
+
+func dateOf(t time.Time) *photoslibrary.Date {
+	return &photoslibrary.Date{Year: int64(t.Year()), Month: int64(t.Month()), Day: int64(t.Day())}
+}
@@ -0,0 +1,64 @@
+package download
+
+import (
+	"encoding/json"
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// tracksBucket is the BoltDB bucket used to persist which media items have already been pulled.
+var tracksBucket = []byte("DownloadTracks")
+
+// Track is what Tracker persists for every media item it has downloaded.
+type Track struct {
+	CreationTime string `json:"creationTime"`
+	Path         string `json:"path"`
+}
+
+// Tracker keeps track of media items that have already been downloaded to local disk, so
+// repeated `pull` runs can skip them. It's the download-side counterpart of FileTracker.
+type Tracker struct {
+	db *bolt.DB
+}
+
+// NewTracker returns a Tracker backed by the given BoltDB database, creating its bucket if needed.
+func NewTracker(db *bolt.DB) (*Tracker, error) {
+	err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(tracksBucket)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not create tracker bucket: %s", err)
+	}
+	return &Tracker{db: db}, nil
+}
+
+// IsTracked reports whether mediaItemID was already downloaded with the given creationTime.
+func (t *Tracker) IsTracked(mediaItemID, creationTime string) bool {
+	var found bool
+	_ = t.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(tracksBucket).Get([]byte(mediaItemID))
+		if data == nil {
+			return nil
+		}
+		var track Track
+		if err := json.Unmarshal(data, &track); err != nil {
+			return nil
+		}
+		found = track.CreationTime == creationTime
+		return nil
+	})
+	return found
+}
+
+// MarkAsTracked records that mediaItemID has been downloaded to path.
+func (t *Tracker) MarkAsTracked(mediaItemID, creationTime, path string) error {
+	data, err := json.Marshal(Track{CreationTime: creationTime, Path: path})
+	if err != nil {
+		return err
+	}
+	return t.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(tracksBucket).Put([]byte(mediaItemID), data)
+	})
+}
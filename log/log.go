@@ -0,0 +1,47 @@
+// Package log provides the small logging interface shared by app.Client and the job types it
+// hands out, so cmd/ and the worker pool don't depend on a concrete logging library.
+package log
+
+import (
+	"fmt"
+	"log"
+)
+
+// Interface is the logger every subcommand and job receives. Debugf is for verbose,
+// development-only detail; Infof is normal progress; Failf is a recoverable per-item error;
+// Fatalf logs and terminates the process.
+type Interface interface {
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Failf(format string, args ...interface{})
+	Fatalf(format string, args ...interface{})
+}
+
+// stdLogger is the default Interface implementation, writing to the standard library logger.
+type stdLogger struct {
+	debug bool
+}
+
+// New returns a logger that writes to stderr. When debug is false, Debugf calls are discarded.
+func New(debug bool) Interface {
+	return &stdLogger{debug: debug}
+}
+
+func (l *stdLogger) Debugf(format string, args ...interface{}) {
+	if !l.debug {
+		return
+	}
+	log.Print("[DEBUG] " + fmt.Sprintf(format, args...))
+}
+
+func (l *stdLogger) Infof(format string, args ...interface{}) {
+	log.Print("[INFO] " + fmt.Sprintf(format, args...))
+}
+
+func (l *stdLogger) Failf(format string, args ...interface{}) {
+	log.Print("[FAIL] " + fmt.Sprintf(format, args...))
+}
+
+func (l *stdLogger) Fatalf(format string, args ...interface{}) {
+	log.Fatal("[FATAL] " + fmt.Sprintf(format, args...))
+}
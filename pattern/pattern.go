@@ -0,0 +1,174 @@
+// Package pattern compiles a rclone-googlephotos-style layout template, such as
+// "album/{album}/{year}/{month}/{filename}", into something that can both render a path for a
+// known media item (used by the pull command) and parse an album/date hint back out of an
+// existing local path (used by upload.AlbumForPath on push). It replaces the old boolean
+// MakeAlbums.Enabled/MakeAlbums.Use toggle with a single, more expressive layout string shared
+// between push and pull.
+package pattern
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// placeholders lists the names a layout template may use, plus the regex each one expands to
+// when a segment is compiled for Parse.
+var placeholders = map[string]string{
+	"album":    `(?P<album>[^/]+)`,
+	"year":     `(?P<year>\d{4})`,
+	"month":    `(?P<month>\d{2})`,
+	"day":      `(?P<day>\d{2})`,
+	"filename": `(?P<filename>[^/]+)`,
+	"ext":      `(?P<ext>[^/.]+)`,
+}
+
+var placeholderRe = regexp.MustCompile(`\{([a-z]+)\}`)
+
+// Fields is the set of values a Pattern renders from or parses into. Fields that a given
+// template doesn't reference are simply ignored.
+type Fields struct {
+	Album    string
+	Year     string
+	Month    string
+	Day      string
+	Filename string
+	Ext      string
+}
+
+// Hint is what Parse recovers from an existing local path: the album it should belong to and,
+// if the layout encodes a date, a creation-time hint for files whose own metadata lacks one.
+type Hint struct {
+	Album        string
+	CreationHint string // "YYYY-MM-DD", with missing components omitted (e.g. "YYYY-MM")
+}
+
+// Pattern is a compiled layout template.
+type Pattern struct {
+	template string
+	segments []string
+	matchers []*regexp.Regexp
+}
+
+// Compile parses a layout template into a Pattern, or returns an error naming the offending
+// segment if the template references an unknown placeholder.
+func Compile(template string) (*Pattern, error) {
+	template = strings.Trim(template, "/")
+	segments := strings.Split(template, "/")
+
+	matchers := make([]*regexp.Regexp, len(segments))
+	for i, segment := range segments {
+		expr, err := segmentRegexp(segment)
+		if err != nil {
+			return nil, fmt.Errorf("invalid layout segment %q: %s", segment, err)
+		}
+		matchers[i] = expr
+	}
+
+	return &Pattern{template: template, segments: segments, matchers: matchers}, nil
+}
+
+// String returns the original layout template.
+func (p *Pattern) String() string {
+	return p.template
+}
+
+// Render expands the template into a relative path using fields.
+func (p *Pattern) Render(fields Fields) string {
+	rendered := make([]string, len(p.segments))
+	for i, segment := range p.segments {
+		rendered[i] = placeholderRe.ReplaceAllStringFunc(segment, func(m string) string {
+			switch placeholderRe.FindStringSubmatch(m)[1] {
+			case "album":
+				return fields.Album
+			case "year":
+				return fields.Year
+			case "month":
+				return fields.Month
+			case "day":
+				return fields.Day
+			case "filename":
+				return fields.Filename
+			case "ext":
+				return fields.Ext
+			default:
+				return m
+			}
+		})
+	}
+	return strings.Join(rendered, "/")
+}
+
+// Parse matches an existing relative local path against the template and recovers the album
+// and/or date hint it encodes. It returns false if path doesn't match the template's shape.
+func (p *Pattern) Parse(path string) (Hint, bool) {
+	path = strings.Trim(path, "/")
+	parts := strings.Split(path, "/")
+	if len(parts) != len(p.segments) {
+		return Hint{}, false
+	}
+
+	var hint Hint
+	var year, month, day string
+
+	for i, part := range parts {
+		m := p.matchers[i].FindStringSubmatch(part)
+		if m == nil {
+			return Hint{}, false
+		}
+		for j, name := range p.matchers[i].SubexpNames() {
+			if name == "" {
+				continue
+			}
+			switch name {
+			case "album":
+				hint.Album = m[j]
+			case "year":
+				year = m[j]
+			case "month":
+				month = m[j]
+			case "day":
+				day = m[j]
+			}
+		}
+	}
+
+	if year != "" || month != "" || day != "" {
+		hint.CreationHint = strings.Join(nonEmpty(year, month, day), "-")
+	}
+
+	return hint, true
+}
+
+// segmentRegexp anchors a path segment's literal text and expands any placeholders it contains.
+// Literal text is quoted piece by piece so braces belonging to a placeholder aren't escaped.
+func segmentRegexp(segment string) (*regexp.Regexp, error) {
+	var expr strings.Builder
+	expr.WriteString("^")
+
+	last := 0
+	for _, loc := range placeholderRe.FindAllStringSubmatchIndex(segment, -1) {
+		name := segment[loc[2]:loc[3]]
+		pattern, ok := placeholders[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown placeholder {%s}", name)
+		}
+		expr.WriteString(regexp.QuoteMeta(segment[last:loc[0]]))
+		expr.WriteString(pattern)
+		last = loc[1]
+	}
+	expr.WriteString(regexp.QuoteMeta(segment[last:]))
+	expr.WriteString("$")
+
+	return regexp.Compile(expr.String())
+}
+
+func nonEmpty(values ...string) []string {
+	var out []string
+	for _, v := range values {
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
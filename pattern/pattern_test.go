@@ -0,0 +1,69 @@
+package pattern
+
+import "testing"
+
+func TestCompile_RejectsUnknownPlaceholder(t *testing.T) {
+	if _, err := Compile("album/{bogus}/{filename}"); err == nil {
+		t.Fatal("expected an error for an unknown placeholder")
+	}
+}
+
+func TestRender(t *testing.T) {
+	p, err := Compile("album/{album}/{year}/{month}/{filename}")
+	if err != nil {
+		t.Fatalf("Compile: %s", err)
+	}
+
+	got := p.Render(Fields{Album: "Trip", Year: "2024", Month: "07", Filename: "photo.jpg"})
+	want := "album/Trip/2024/07/photo.jpg"
+	if got != want {
+		t.Fatalf("Render() = %q, want %q", got, want)
+	}
+}
+
+func TestParse(t *testing.T) {
+	p, err := Compile("album/{album}/{year}/{month}/{day}/{filename}")
+	if err != nil {
+		t.Fatalf("Compile: %s", err)
+	}
+
+	hint, ok := p.Parse("album/Trip/2024/07/04/photo.jpg")
+	if !ok {
+		t.Fatal("expected Parse to match")
+	}
+	if hint.Album != "Trip" {
+		t.Fatalf("Album = %q, want %q", hint.Album, "Trip")
+	}
+	if hint.CreationHint != "2024-07-04" {
+		t.Fatalf("CreationHint = %q, want %q", hint.CreationHint, "2024-07-04")
+	}
+}
+
+func TestParse_ReturnsFalseOnShapeMismatch(t *testing.T) {
+	p, err := Compile("album/{album}/{filename}")
+	if err != nil {
+		t.Fatalf("Compile: %s", err)
+	}
+
+	if _, ok := p.Parse("album/Trip/2024/photo.jpg"); ok {
+		t.Fatal("expected Parse to reject a path with more segments than the template")
+	}
+}
+
+func TestRenderThenParse_RoundTrips(t *testing.T) {
+	p, err := Compile("by-date/{year}-{month}-{day}/{filename}")
+	if err != nil {
+		t.Fatalf("Compile: %s", err)
+	}
+
+	fields := Fields{Year: "2024", Month: "07", Day: "04", Filename: "photo.jpg"}
+	rendered := p.Render(fields)
+
+	hint, ok := p.Parse(rendered)
+	if !ok {
+		t.Fatalf("Parse(%q) did not match its own Render output", rendered)
+	}
+	if hint.CreationHint != "2024-07-04" {
+		t.Fatalf("CreationHint = %q, want %q", hint.CreationHint, "2024-07-04")
+	}
+}
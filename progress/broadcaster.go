@@ -0,0 +1,54 @@
+package progress
+
+import "sync"
+
+// Broadcaster fans a stream of Events out to any number of subscribers. Publishing never blocks:
+// a subscriber whose channel is full simply misses that event instead of stalling the pipeline.
+type Broadcaster struct {
+	mu          sync.Mutex
+	subscribers []chan Event
+}
+
+// NewBroadcaster returns an empty Broadcaster.
+func NewBroadcaster() *Broadcaster {
+	return &Broadcaster{}
+}
+
+// Subscribe returns a channel that receives every Event published from now on. Call Unsubscribe
+// when done with it to release its buffer.
+func (b *Broadcaster) Subscribe() <-chan Event {
+	ch := make(chan Event, 64)
+
+	b.mu.Lock()
+	b.subscribers = append(b.subscribers, ch)
+	b.mu.Unlock()
+
+	return ch
+}
+
+// Unsubscribe stops and closes a channel previously returned by Subscribe.
+func (b *Broadcaster) Unsubscribe(ch <-chan Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for i, sub := range b.subscribers {
+		if sub == ch {
+			close(sub)
+			b.subscribers = append(b.subscribers[:i], b.subscribers[i+1:]...)
+			return
+		}
+	}
+}
+
+// Publish sends event to every current subscriber, dropping it for any subscriber whose buffer is full.
+func (b *Broadcaster) Publish(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, sub := range b.subscribers {
+		select {
+		case sub <- event:
+		default:
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package progress
+
+import "testing"
+
+func TestBroadcaster_PublishDeliversToSubscribers(t *testing.T) {
+	b := NewBroadcaster()
+	sub := b.Subscribe()
+
+	b.Publish(Event{JobID: "1", Phase: PhaseUploading})
+
+	select {
+	case got := <-sub:
+		if got.JobID != "1" || got.Phase != PhaseUploading {
+			t.Fatalf("got %+v, want JobID=1 Phase=uploading", got)
+		}
+	default:
+		t.Fatal("expected event to be delivered to subscriber")
+	}
+}
+
+func TestBroadcaster_PublishWithNoSubscribersDoesNotBlock(t *testing.T) {
+	b := NewBroadcaster()
+	b.Publish(Event{JobID: "1"})
+}
+
+func TestBroadcaster_PublishDropsOnFullSubscriberBuffer(t *testing.T) {
+	b := NewBroadcaster()
+	sub := b.Subscribe()
+
+	for i := 0; i < 100; i++ {
+		b.Publish(Event{JobID: "1"})
+	}
+
+	// Draining should yield at most the buffer's capacity worth of events, not block or panic.
+	count := 0
+	for {
+		select {
+		case <-sub:
+			count++
+		default:
+			if count == 0 {
+				t.Fatal("expected at least one event to have been buffered")
+			}
+			if count > cap(sub) {
+				t.Fatalf("drained %d events, want at most the buffer capacity %d", count, cap(sub))
+			}
+			return
+		}
+	}
+}
+
+func TestBroadcaster_UnsubscribeStopsDelivery(t *testing.T) {
+	b := NewBroadcaster()
+	sub := b.Subscribe()
+	b.Unsubscribe(sub)
+
+	b.Publish(Event{JobID: "1"})
+
+	if _, ok := <-sub; ok {
+		t.Fatal("expected channel to be closed after Unsubscribe")
+	}
+}
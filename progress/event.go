@@ -0,0 +1,22 @@
+package progress
+
+// Phase identifies which stage of processing an Event refers to.
+type Phase string
+
+const (
+	PhaseQueued      Phase = "queued"
+	PhaseUploading   Phase = "uploading"
+	PhaseDownloading Phase = "downloading"
+	PhaseDone        Phase = "done"
+	PhaseFailed      Phase = "failed"
+)
+
+// Event describes the progress of a single job, in bytes. It lets frontends other than the log
+// output (a future TUI, an HTTP status endpoint, ...) follow along without scraping stderr.
+type Event struct {
+	JobID      string
+	Path       string
+	BytesDone  int64
+	BytesTotal int64
+	Phase      Phase
+}
@@ -0,0 +1,57 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+// ErrQuotaExceeded is returned by Retry once it gives up after exhausting its retries against a
+// 429/quotaExceeded response, so callers can tell quota exhaustion apart from a real error.
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+const maxRetries = 5
+
+// Retry calls fn, retrying with exponential backoff plus jitter whenever fn's error is a 429
+// from the Photos Library API. It gives up with ErrQuotaExceeded after maxRetries retries, i.e.
+// maxRetries+1 calls to fn in all.
+func Retry(ctx context.Context, fn func() error) error {
+	var err error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		err = fn()
+		if err == nil || !isQuotaExceeded(err) {
+			return err
+		}
+
+		if attempt == maxRetries {
+			return ErrQuotaExceeded
+		}
+
+		wait := backoff(attempt)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return err
+}
+
+func isQuotaExceeded(err error) bool {
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// backoff returns 2^attempt seconds, plus up to 1s of jitter, to avoid every worker retrying in lockstep.
+func backoff(attempt int) time.Duration {
+	base := time.Duration(1<<attempt) * time.Second
+	jitter := time.Duration(rand.Int63n(int64(time.Second)))
+	return base + jitter
+}
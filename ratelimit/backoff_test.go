@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+func TestRetry_ReturnsImmediatelyOnSuccess(t *testing.T) {
+	calls := 0
+	err := Retry(context.Background(), func() error {
+		calls++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Retry() = %v, want nil", err)
+	}
+	if calls != 1 {
+		t.Fatalf("fn called %d times, want 1", calls)
+	}
+}
+
+func TestRetry_DoesNotRetryNonQuotaErrors(t *testing.T) {
+	wantErr := errors.New("boom")
+	calls := 0
+	err := Retry(context.Background(), func() error {
+		calls++
+		return wantErr
+	})
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("Retry() = %v, want %v", err, wantErr)
+	}
+	if calls != 1 {
+		t.Fatalf("fn called %d times, want 1", calls)
+	}
+}
+
+func TestRetry_AbortsOnContextCancellation(t *testing.T) {
+	quotaErr := &googleapi.Error{Code: 429}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	calls := 0
+	err := Retry(ctx, func() error {
+		calls++
+		cancel()
+		return quotaErr
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Retry() = %v, want %v", err, context.Canceled)
+	}
+	if calls != 1 {
+		t.Fatalf("fn called %d times, want 1", calls)
+	}
+}
+
+func TestBackoff_IsWithinExpectedBounds(t *testing.T) {
+	for attempt := 0; attempt < 5; attempt++ {
+		d := backoff(attempt)
+		min := time.Duration(1<<attempt) * time.Second
+		max := min + time.Second
+		if d < min || d >= max {
+			t.Fatalf("backoff(%d) = %s, want in [%s, %s)", attempt, d, min, max)
+		}
+	}
+}
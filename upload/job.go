@@ -0,0 +1,101 @@
+package upload
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	gphotos "github.com/gphotosuploader/google-photos-api-client-go/lib-gphotos"
+
+	"github.com/gphotosuploader/gphotos-uploader-cli/log"
+	"github.com/gphotosuploader/gphotos-uploader-cli/progress"
+	"github.com/gphotosuploader/gphotos-uploader-cli/ratelimit"
+	"github.com/gphotosuploader/gphotos-uploader-cli/worker"
+)
+
+// EnqueuedJob uploads a single local file to Google Photos, optionally adding it to an album.
+// It's the push-side counterpart of worker.DownloadJob.
+type EnqueuedJob struct {
+	Context       context.Context
+	PhotosService *gphotos.Client
+	FileTracker   *FileTracker
+	Logger        log.Interface
+	Broadcaster   *progress.Broadcaster
+	Limiter       *ratelimit.Limiter
+
+	Path            string
+	AlbumName       string
+	DeleteOnSuccess bool
+}
+
+// Process uploads the file and records it in the FileTracker.
+func (j *EnqueuedJob) Process() worker.JobResult {
+	var mediaID string
+	err := ratelimit.Retry(j.Context, func() error {
+		id, err := j.upload()
+		if err != nil {
+			return err
+		}
+		mediaID = id
+		return nil
+	})
+	if err != nil {
+		return worker.JobResult{ID: j.Path, Err: err}
+	}
+
+	if err := j.FileTracker.MarkAsUploaded(j.Path, mediaID); err != nil {
+		return worker.JobResult{ID: j.Path, Err: err}
+	}
+
+	if j.DeleteOnSuccess {
+		if err := os.Remove(j.Path); err != nil {
+			j.Logger.Failf("Uploaded %s but could not delete it: %s", j.Path, err)
+		}
+	}
+
+	return worker.JobResult{ID: j.Path}
+}
+
+// upload acquires a concurrent-upload slot and a rate-limit token before sending the request, so
+// a job with many workers doesn't blow through the account's request quota.
+func (j *EnqueuedJob) upload() (string, error) {
+	release, err := j.Limiter.AcquireUploadSlot(j.Context)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	if err := j.Limiter.WaitForRequest(j.Context); err != nil {
+		return "", err
+	}
+
+	file, err := os.Open(j.Path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	var reader io.Reader = file
+	if j.Broadcaster != nil {
+		reader = &ProgressReader{
+			Reader:      file,
+			Broadcaster: j.Broadcaster,
+			JobID:       j.Path,
+			Path:        j.Path,
+			Total:       info.Size(),
+		}
+	}
+
+	mediaItem, err := j.PhotosService.UploadFileToAlbum(j.Context, j.AlbumName, j.Path, reader)
+	if err != nil {
+		return "", fmt.Errorf("could not upload %s: %s", j.Path, err)
+	}
+
+	return mediaItem.Id, nil
+}
@@ -0,0 +1,36 @@
+package upload
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/gphotosuploader/gphotos-uploader-cli/pattern"
+)
+
+// AlbumForPath derives the album name a local file should be uploaded under, by parsing its path
+// relative to SourceFolder against layout. cmd.PushCmd calls this per scanned item instead of
+// relying on ScanFolder's CreateAlbumBasedOn convention when layout is set.
+//
+// layout.Parse also returns a creation-time hint, but push has nowhere to put it: the Library
+// API derives creation time from the uploaded file's own EXIF/metadata, not from an upload
+// parameter, so the hint is discarded. Only pull uses the layout's date fields, rendering them
+// from each item's creation time (see download.FolderJob.destPathFor).
+func AlbumForPath(layout *pattern.Pattern, sourceFolder, path string) (album string) {
+	rel, err := filepath.Rel(sourceFolder, path)
+	if err != nil {
+		return ""
+	}
+
+	hint, ok := layout.Parse(filepath.ToSlash(rel))
+	if !ok {
+		return ""
+	}
+
+	return hint.Album
+}
+
+// HasAlbumPlaceholder reports whether layout renders a per-directory album name, which
+// ScanFolder uses to decide whether it needs to create albums at all.
+func HasAlbumPlaceholder(layout *pattern.Pattern) bool {
+	return strings.Contains(layout.String(), "{album}")
+}
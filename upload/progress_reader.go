@@ -0,0 +1,35 @@
+package upload
+
+import (
+	"io"
+
+	"github.com/gphotosuploader/gphotos-uploader-cli/progress"
+)
+
+// ProgressReader wraps an io.Reader and publishes a progress.Event on every Read, so a per-file
+// progress bar can track byte-level upload progress without depending on stderr scraping.
+type ProgressReader struct {
+	io.Reader
+
+	Broadcaster *progress.Broadcaster
+	JobID       string
+	Path        string
+	Total       int64
+
+	done int64
+}
+
+func (r *ProgressReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if n > 0 && r.Broadcaster != nil {
+		r.done += int64(n)
+		r.Broadcaster.Publish(progress.Event{
+			JobID:      r.JobID,
+			Path:       r.Path,
+			BytesDone:  r.done,
+			BytesTotal: r.Total,
+			Phase:      progress.PhaseUploading,
+		})
+	}
+	return n, err
+}
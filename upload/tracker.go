@@ -0,0 +1,130 @@
+package upload
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// uploadsBucket is the BoltDB bucket used to persist which local files have already been
+// uploaded, so repeated `push` runs can skip them. It's the upload-side counterpart of
+// download.Tracker.
+var uploadsBucket = []byte("FileTracker")
+
+// upload is what FileTracker persists for every local file it has uploaded.
+type upload struct {
+	ModTime int64  `json:"modTime"`
+	MediaID string `json:"mediaId"`
+}
+
+// FileTracker keeps track of local files that have already been uploaded to Google Photos,
+// keyed by path and last-modified time, so a re-run doesn't re-upload unchanged files.
+type FileTracker struct {
+	db *bolt.DB
+}
+
+// NewFileTracker returns a FileTracker backed by the given BoltDB database, creating its bucket
+// if needed.
+func NewFileTracker(db *bolt.DB) (*FileTracker, error) {
+	err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(uploadsBucket)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not create file tracker bucket: %s", err)
+	}
+	return &FileTracker{db: db}, nil
+}
+
+// IsTracked reports whether path was already uploaded and hasn't been modified since.
+func (t *FileTracker) IsTracked(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+
+	var found bool
+	_ = t.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(uploadsBucket).Get([]byte(path))
+		if data == nil {
+			return nil
+		}
+		var u upload
+		if err := json.Unmarshal(data, &u); err != nil {
+			return nil
+		}
+		found = u.ModTime == info.ModTime().Unix()
+		return nil
+	})
+	return found
+}
+
+// MarkAsUploaded records that path has been uploaded as mediaID.
+func (t *FileTracker) MarkAsUploaded(path, mediaID string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(upload{ModTime: info.ModTime().Unix(), MediaID: mediaID})
+	if err != nil {
+		return err
+	}
+	return t.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(uploadsBucket).Put([]byte(path), data)
+	})
+}
+
+// resumablesBucket is the BoltDB bucket the resumable-uploads client uses to persist in-progress
+// upload session URLs, so an interrupted upload can resume instead of starting over.
+var resumablesBucket = []byte("ResumableUploads")
+
+// ResumableTracker persists resumable-upload session state, satisfying the uploader.UploadSessionStore
+// interface expected by gphotos.NewClientWithResumableUploads. That interface has no way to
+// surface an error, so a failed BoltDB read or write is treated the same as "nothing stored":
+// worst case the upload restarts from scratch instead of resuming.
+type ResumableTracker struct {
+	db *bolt.DB
+}
+
+// NewResumableTracker returns a ResumableTracker backed by the given BoltDB database, creating
+// its bucket if needed.
+func NewResumableTracker(db *bolt.DB) (*ResumableTracker, error) {
+	err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(resumablesBucket)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not create resumable uploads bucket: %s", err)
+	}
+	return &ResumableTracker{db: db}, nil
+}
+
+// Get returns the in-progress upload session data previously stored under key, or nil if there
+// is none.
+func (t *ResumableTracker) Get(key string) []byte {
+	var data []byte
+	_ = t.db.View(func(tx *bolt.Tx) error {
+		if v := tx.Bucket(resumablesBucket).Get([]byte(key)); v != nil {
+			data = append([]byte(nil), v...)
+		}
+		return nil
+	})
+	return data
+}
+
+// Set stores the in-progress upload session data under key.
+func (t *ResumableTracker) Set(key string, value []byte) {
+	_ = t.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(resumablesBucket).Put([]byte(key), value)
+	})
+}
+
+// Delete drops the in-progress upload session data for key, e.g. once the upload has completed.
+func (t *ResumableTracker) Delete(key string) {
+	_ = t.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(resumablesBucket).Delete([]byte(key))
+	})
+}
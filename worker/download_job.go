@@ -0,0 +1,113 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/gphotosuploader/gphotos-uploader-cli/download"
+	"github.com/gphotosuploader/gphotos-uploader-cli/progress"
+	"github.com/gphotosuploader/gphotos-uploader-cli/ratelimit"
+)
+
+// DownloadJob downloads a single media item original to local disk. It's the pull-side
+// counterpart of upload.EnqueuedJob.
+type DownloadJob struct {
+	Context     context.Context
+	Tracker     *download.Tracker
+	Broadcaster *progress.Broadcaster
+	Limiter     *ratelimit.Limiter
+
+	MediaItemID  string
+	BaseURL      string
+	CreationTime string
+	IsVideo      bool
+	DestPath     string
+}
+
+// Process downloads the original bytes for the media item and records it in the Tracker.
+func (j *DownloadJob) Process() JobResult {
+	if err := ratelimit.Retry(j.Context, j.download); err != nil {
+		return JobResult{ID: j.MediaItemID, Err: err}
+	}
+	if err := j.Tracker.MarkAsTracked(j.MediaItemID, j.CreationTime, j.DestPath); err != nil {
+		return JobResult{ID: j.MediaItemID, Err: err}
+	}
+	return JobResult{ID: j.MediaItemID}
+}
+
+func (j *DownloadJob) download() error {
+	release, err := j.Limiter.AcquireUploadSlot(j.Context)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if err := os.MkdirAll(filepath.Dir(j.DestPath), 0755); err != nil {
+		return fmt.Errorf("could not create destination folder: %s", err)
+	}
+
+	req, err := http.NewRequestWithContext(j.Context, http.MethodGet, download.OriginalURL(j.BaseURL, j.IsVideo), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status downloading %s: %s", j.MediaItemID, resp.Status)
+	}
+
+	out, err := os.Create(j.DestPath)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	body := io.Reader(resp.Body)
+	if j.Broadcaster != nil {
+		body = &progressReader{
+			Reader:      body,
+			broadcaster: j.Broadcaster,
+			jobID:       j.MediaItemID,
+			path:        j.DestPath,
+			total:       resp.ContentLength,
+		}
+	}
+
+	_, err = io.Copy(out, body)
+	return err
+}
+
+// progressReader wraps an io.Reader and publishes a progress.Event on every Read.
+type progressReader struct {
+	io.Reader
+
+	broadcaster *progress.Broadcaster
+	jobID       string
+	path        string
+	total       int64
+	done        int64
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if n > 0 {
+		r.done += int64(n)
+		r.broadcaster.Publish(progress.Event{
+			JobID:      r.jobID,
+			Path:       r.path,
+			BytesDone:  r.done,
+			BytesTotal: r.total,
+			Phase:      progress.PhaseDownloading,
+		})
+	}
+	return n, err
+}